@@ -1,13 +1,23 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"net/http"
 	"os"
+	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/bneil/voter/internal/api"
+	"github.com/bneil/voter/internal/config"
 	"github.com/bneil/voter/internal/metrics"
 	"github.com/bneil/voter/internal/models"
 	"github.com/bneil/voter/internal/project"
@@ -16,267 +26,936 @@ import (
 )
 
 func main() {
-	if len(os.Args) < 2 {
+	flags, cliArgs, err := extractGlobalFlags(os.Args[1:])
+	if err != nil {
+		fail(invalidArgument(err), "Invalid arguments")
+	}
+	if flags.output != "" {
+		if output, err = parseOutputFormat(flags.output); err != nil {
+			output = outputText
+			fail(invalidArgument(err), "Invalid --output value")
+		}
+	}
+
+	if len(cliArgs) < 1 {
 		printUsage()
-		os.Exit(1)
+		os.Exit(exitUsage)
+	}
+
+	if flags.ifVersion != "" {
+		if !versionedCommands[cliArgs[0]] {
+			err := fmt.Errorf("%s does not accept --if-version", cliArgs[0])
+			failWith(fmt.Errorf("%w: %w", errUsage, err), err.Error())
+		}
+		version, err := strconv.ParseInt(flags.ifVersion, 10, 64)
+		if err != nil || version < 1 {
+			fail(invalidArgument(fmt.Errorf("--if-version must be a positive version number, got %q", flags.ifVersion)), "Invalid --if-version value")
+		}
+		preconditions = []project.Precondition{project.IfVersion(version)}
 	}
 
+	configPath, err := config.Find(flags.configPath)
+	if err != nil {
+		fail(invalidArgument(err), "Failed to load config")
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fail(err, "Failed to load config")
+	}
+	if flags.output == "" && cfg.Output != "" {
+		if output, err = parseOutputFormat(cfg.Output); err != nil {
+			output = outputText
+			fail(fmt.Errorf("%w: %s: %w", config.ErrInvalidConfig, cfg.Path, err), "Failed to load config")
+		}
+	}
+	cfg.DataDir = cfg.ResolveDataDir(flags.dataDir)
+	cfg.Output = string(output)
+
 	// Initialize dependencies
-	store, err := storage.NewJSONProjectStore("./data")
+	store, voteStore, err := storage.Open(cfg.Store, cfg.DataDir)
 	if err != nil {
-		log.Fatalf("Failed to initialize storage: %v", err)
+		fail(err, "Failed to initialize storage")
 	}
 
 	votingService := project.NewVotingService()
-	projectService := project.NewService(store, votingService)
+	projectService := project.NewService(store, voteStore, votingService)
 	enhancedVoting := voting.NewEnhancedVotingService()
-	enhancedVoting.InitializeStrategies()
+	enhancedVoting.InitializeSeededStrategies(cfg.StrategySeeds)
+	projectService.SetEscalationHandler(func(project *models.Project, decision *models.Decision) {
+		notice("Decision %s escalated: %s\n", decision.ID, decision.ResolutionReason)
+	})
+	progression := project.NewProgressionManager(projectService)
 	scorer := metrics.NewScorer()
-	metricsTracker := metrics.NewTracker()
+	metricsTracker, err := metrics.TrackService(projectService, voteStore, cfg.DataDir, scorer, func(err error) {
+		notice("Warning: %v\n", err)
+	})
+	if err != nil {
+		fail(err, "Failed to load metrics")
+	}
 
-	command := os.Args[1]
-	args := os.Args[2:]
+	command := cliArgs[0]
+	args := cliArgs[1:]
 
 	switch command {
 	case "create-project":
-		handleCreateProject(projectService, args)
+		handleCreateProject(projectService, cfg, args)
 	case "start-decision":
 		handleStartDecision(projectService, args)
 	case "vote":
 		handleVote(projectService, args)
 	case "close-voting":
 		handleCloseVoting(projectService, args)
+	case "cancel-project":
+		handleCancelProject(projectService, args)
+	case "cancel-decision":
+		handleCancelDecision(projectService, args)
+	case "pause-project":
+		handlePauseProject(projectService, args)
+	case "resume-project":
+		handleResumeProject(projectService, args)
 	case "project-status":
 		handleProjectStatus(projectService, scorer, args)
 	case "list-projects":
 		handleListProjects(projectService, args)
 	case "project-stats":
 		handleProjectStats(metricsTracker, args)
+	case "rebuild-stats":
+		handleRebuildStats(projectService, voteStore, metricsTracker, scorer, args)
+	case "strategy-stats":
+		handleStrategyStats(metricsTracker, args)
 	case "simulate-voting":
 		handleSimulateVoting(projectService, enhancedVoting, args)
 	case "strategic-vote":
 		handleStrategicVote(projectService, enhancedVoting, args)
+	case "sweep":
+		handleSweep(projectService, args)
+	case "serve":
+		handleServe(projectService, progression, args)
+	case "watch":
+		handleWatch(args)
+	case "advance":
+		handleAdvance(progression, args)
+	case "progress":
+		handleProgress(progression, args)
+	case "replay":
+		handleReplay(projectService, args)
+	case "config":
+		handleConfig(cfg, args)
 	default:
-		fmt.Printf("Unknown command: %s\n", command)
-		printUsage()
-		os.Exit(1)
+		if output == outputText {
+			fmt.Printf("Unknown command: %s\n", command)
+			printUsage()
+			os.Exit(exitUsage)
+		}
+		failWith(fmt.Errorf("%w: unknown command %s", errUsage, command), "")
 	}
 }
 
-func handleCreateProject(service *project.Service, args []string) {
-	if len(args) < 3 {
-		fmt.Println("Usage: create-game <id> <name> <k> <max-turns>")
-		os.Exit(1)
+func handleCreateProject(service *project.Service, cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("create-project", flag.ContinueOnError)
+	revotePolicy := fs.String("revote-policy", string(models.RevotePolicyRejectDuplicate),
+		"how repeat votes from an agent are handled: reject-duplicate, replace-previous or allow-multiple")
+	tieBreak := fs.String("tie-break", string(models.TieBreakFirstToReach),
+		"how tied vote counts are ordered: first-to-reach, declaration-order or earliest-vote")
+	var normalizers []models.NormalizerSpec
+	fs.Func("normalize", "answer normalizer to apply before tallying (repeatable): trim, casefold, unicode, json or regex:<pattern>=><replacement>",
+		func(value string) error {
+			spec, err := voting.ParseNormalizerSpec(value)
+			if err != nil {
+				return err
+			}
+			normalizers = append(normalizers, spec)
+			return nil
+		})
+	var redFlags models.RedFlagRules
+	fs.IntVar(&redFlags.MaxLength, "max-answer-length", 0, "discard answers longer than this many characters")
+	fs.StringVar(&redFlags.RequiredPattern, "require-pattern", "", "discard answers that do not fully match this regex")
+	fs.BoolVar(&redFlags.RequireJSON, "require-json", false, "discard answers that are not valid JSON")
+	fs.Func("require-json-key", "discard answers that are not a JSON object with this key (repeatable)", func(value string) error {
+		redFlags.RequiredJSONKeys = append(redFlags.RequiredJSONKeys, value)
+		return nil
+	})
+	fs.Func("ban-token", "discard answers containing this text (repeatable)", func(value string) error {
+		redFlags.BannedTokens = append(redFlags.BannedTokens, value)
+		return nil
+	})
+	maxVotes := fs.Int("max-votes", 0, "vote budget per decision before the fallback applies (0 = unlimited)")
+	budgetFallback := fs.String("budget-fallback", string(models.FallbackPlurality),
+		"how a decision is resolved when its vote budget is exhausted: plurality, cancel or escalate")
+	decisionTimeout := fs.Duration("decision-timeout", 0, "default voting window for new decisions, e.g. 10m (0 = no deadline)")
+	timeoutOutcome := fs.String("timeout-outcome", string(models.FallbackPlurality),
+		"how a decision is resolved when its deadline passes: plurality, cancel, escalate or extend")
+	args = parseArgs(fs, args)
+
+	if len(args) < 2 || (len(args) < 3 && cfg.K == 0) {
+		usage(
+			"Usage: create-project [--revote-policy <policy>] [--tie-break <rule>] [--normalize <normalizer>...]",
+			"                      [--max-answer-length <n>] [--require-pattern <regex>] [--require-json]",
+			"                      [--require-json-key <key>...] [--ban-token <text>...]",
+			"                      [--max-votes <n>] [--budget-fallback <action>]",
+			"                      [--decision-timeout <duration>] [--timeout-outcome <action>] <id> <name> <k> [max-turns]",
+			"",
+			"k and max-turns default to the k and max_turns config settings.",
+		)
 	}
 
 	id := args[0]
 	name := args[1]
-	k, err := strconv.Atoi(args[2])
-	if err != nil {
-		fmt.Printf("Invalid K value: %v\n", err)
-		os.Exit(1)
+	k := cfg.K
+	var err error
+	if len(args) > 2 {
+		k, err = strconv.Atoi(args[2])
+		if err != nil {
+			fail(invalidArgument(err), "Invalid K value")
+		}
 	}
 
-	maxTurns := 10 // default
+	maxTurns := cfg.MaxTurns
 	if len(args) > 3 {
 		maxTurns, err = strconv.Atoi(args[3])
 		if err != nil {
-			fmt.Printf("Invalid max-turns value: %v\n", err)
-			os.Exit(1)
+			fail(invalidArgument(err), "Invalid max-turns value")
 		}
 	}
 
-	project, err := service.CreateProject(id, name, k, maxTurns)
+	policy, err := models.ParseRevotePolicy(*revotePolicy)
+	if err != nil {
+		fail(invalidArgument(err), "Invalid re-vote policy")
+	}
+
+	tieBreakRule, err := models.ParseTieBreakRule(*tieBreak)
+	if err != nil {
+		fail(invalidArgument(err), "Invalid tie-break rule")
+	}
+
+	fallback, err := models.ParseFallbackAction(*budgetFallback)
+	if err != nil {
+		fail(invalidArgument(err), "Invalid budget fallback")
+	}
+
+	outcome, err := models.ParseTimeoutOutcome(*timeoutOutcome)
 	if err != nil {
-		fmt.Printf("Failed to create project: %v\n", err)
-		os.Exit(1)
+		fail(invalidArgument(err), "Invalid timeout outcome")
+	}
+
+	settings := models.ProjectSettings{
+		RevotePolicy: policy,
+		TieBreak:     tieBreakRule,
+		Normalizers:  normalizers,
+		RedFlags:     redFlags,
+
+		MaxVotesPerDecision: *maxVotes,
+		BudgetFallback:      fallback,
+
+		DecisionTimeout: *decisionTimeout,
+		TimeoutOutcome:  outcome,
 	}
 
-	fmt.Printf("Project created successfully:\n")
-	printProject(project)
+	project, err := service.CreateProjectWithSettings(id, name, k, maxTurns, settings)
+	if err != nil {
+		fail(err, "Failed to create project")
+	}
+
+	emit(project, func() {
+		fmt.Printf("Project created successfully:\n")
+		printProject(project)
+	})
 }
 
 func handleStartDecision(service *project.Service, args []string) {
-	if len(args) < 3 {
-		fmt.Println("Usage: start-decision <project-id> <description> <option1> <option2> [option3...]")
-		os.Exit(1)
+	fs := flag.NewFlagSet("start-decision", flag.ContinueOnError)
+	open := fs.Bool("open", false, "accept any answer as a candidate; listed options seed the candidates")
+	deadline := fs.String("deadline", "", "close voting at this RFC 3339 time")
+	timeout := fs.Duration("timeout", 0, "close voting after this duration, e.g. 5m")
+	id := fs.String("id", "", "decision ID to use instead of a generated one")
+	args = parseArgs(fs, args)
+
+	if len(args) < 2 || (!*open && len(args) < 3) {
+		usage(
+			"Usage: start-decision [--id <decision-id>] [--deadline <time>] [--timeout <duration>] <project-id> <description> <option1> <option2> [option3...]",
+			"       start-decision --open [--id <decision-id>] [--deadline <time>] [--timeout <duration>] <project-id> <description> [candidate...]",
+		)
 	}
 
 	projectID := args[0]
 	description := args[1]
 	options := args[2:]
 
-	if len(options) < 2 {
-		fmt.Println("At least 2 options required")
-		os.Exit(1)
+	if !*open && len(options) < 2 {
+		failWith(invalidArgument(errors.New("at least 2 options required")), "At least 2 options required")
+	}
+
+	config := project.DecisionConfig{Open: *open, Timeout: *timeout}
+	if *deadline != "" {
+		parsed, err := time.Parse(time.RFC3339, *deadline)
+		if err != nil {
+			fail(invalidArgument(err), "Invalid deadline")
+		}
+		config.Deadline = parsed
 	}
 
-	decision, err := service.StartDecision(projectID, fmt.Sprintf("decision_%d", 1), description, options)
+	_, decision, err := service.StartDecisionWithConfig(projectID, *id, description, options, config, preconditions...)
 	if err != nil {
-		fmt.Printf("Failed to start decision: %v\n", err)
-		os.Exit(1)
+		fail(err, "Failed to start decision")
 	}
 
-	fmt.Printf("Decision started:\n")
-	printDecision(decision)
+	emit(decision, func() {
+		fmt.Printf("Decision ID: %s\n", decision.ID)
+		fmt.Printf("Decision started:\n")
+		printDecision(decision)
+	})
 }
 
 func handleVote(service *project.Service, args []string) {
-	if len(args) < 3 {
-		fmt.Println("Usage: vote <project-id> <decision-id> <agent-id> <option>")
-		os.Exit(1)
+	if len(args) < 4 {
+		usage("Usage: vote <project-id> <decision-id> <agent-id> <option>")
 	}
 
 	projectID := args[0]
 	decisionID := args[1]
 	agentID := args[2]
-	option := args[3]
+	// Free-form answers for open decisions may span several arguments
+	option := strings.Join(args[3:], " ")
 
-	err := service.CastVote(projectID, decisionID, agentID, option)
+	_, err := service.CastVote(projectID, decisionID, agentID, option, preconditions...)
+	if errors.Is(err, project.ErrDuplicateVote) {
+		failWith(err, fmt.Sprintf("Vote rejected: agent %s has already voted on decision %s", agentID, decisionID))
+	}
+	if errors.Is(err, project.ErrProjectPaused) {
+		failWith(err, fmt.Sprintf("Vote rejected: project %s is paused", projectID))
+	}
+	if errors.Is(err, project.ErrVoteFlagged) {
+		fail(err, "Vote discarded")
+	}
 	if err != nil {
-		fmt.Printf("Failed to cast vote: %v\n", err)
-		os.Exit(1)
+		fail(err, "Failed to cast vote")
 	}
 
-	fmt.Printf("Vote cast successfully by agent %s for option '%s'\n", agentID, option)
+	emitResult(commandResult{
+		Command:    "vote",
+		ProjectID:  projectID,
+		DecisionID: decisionID,
+		AgentID:    agentID,
+		Option:     option,
+		Message:    fmt.Sprintf("Vote cast successfully by agent %s for option '%s'", agentID, option),
+	})
 }
 
 func handleCloseVoting(service *project.Service, args []string) {
 	if len(args) < 1 {
-		fmt.Println("Usage: close-voting <project-id>")
-		os.Exit(1)
+		usage("Usage: close-voting <project-id>")
 	}
 
 	projectID := args[0]
 
-	err := service.EndProject(projectID)
+	err := service.EndProject(projectID, preconditions...)
 	if err != nil {
-		fmt.Printf("Failed to close voting: %v\n", err)
-		os.Exit(1)
+		fail(err, "Failed to close voting")
+	}
+
+	emitResult(commandResult{
+		Command:   "close-voting",
+		ProjectID: projectID,
+		Message:   fmt.Sprintf("Voting closed for project %s", projectID),
+	})
+}
+
+func handleCancelProject(service *project.Service, args []string) {
+	if len(args) < 2 {
+		usage("Usage: cancel-project <project-id> <reason...>")
+	}
+
+	projectID := args[0]
+	reason := strings.Join(args[1:], " ")
+
+	if err := service.CancelProject(projectID, reason, preconditions...); err != nil {
+		fail(err, "Failed to cancel project")
+	}
+
+	emitResult(commandResult{
+		Command:   "cancel-project",
+		ProjectID: projectID,
+		Reason:    reason,
+		Message:   fmt.Sprintf("Project %s cancelled: %s", projectID, reason),
+	})
+}
+
+func handleCancelDecision(service *project.Service, args []string) {
+	if len(args) < 3 {
+		usage("Usage: cancel-decision <project-id> <decision-id> <reason...>")
+	}
+
+	projectID := args[0]
+	decisionID := args[1]
+	reason := strings.Join(args[2:], " ")
+
+	if err := service.CancelDecision(projectID, decisionID, reason, preconditions...); err != nil {
+		fail(err, "Failed to cancel decision")
+	}
+
+	emitResult(commandResult{
+		Command:    "cancel-decision",
+		ProjectID:  projectID,
+		DecisionID: decisionID,
+		Reason:     reason,
+		Message:    fmt.Sprintf("Decision %s cancelled: %s", decisionID, reason),
+	})
+}
+
+func handlePauseProject(service *project.Service, args []string) {
+	if len(args) < 1 {
+		usage("Usage: pause-project <project-id>")
+	}
+
+	projectID := args[0]
+
+	if err := service.PauseProject(projectID, preconditions...); err != nil {
+		fail(err, "Failed to pause project")
+	}
+
+	emitResult(commandResult{
+		Command:   "pause-project",
+		ProjectID: projectID,
+		Message:   fmt.Sprintf("Project %s paused", projectID),
+	})
+}
+
+func handleResumeProject(service *project.Service, args []string) {
+	if len(args) < 1 {
+		usage("Usage: resume-project <project-id>")
+	}
+
+	projectID := args[0]
+
+	if err := service.ResumeProject(projectID, preconditions...); err != nil {
+		fail(err, "Failed to resume project")
 	}
 
-	fmt.Printf("Voting closed for project %s\n", projectID)
+	emitResult(commandResult{
+		Command:   "resume-project",
+		ProjectID: projectID,
+		Message:   fmt.Sprintf("Project %s resumed", projectID),
+	})
 }
 
 func handleProjectStatus(service *project.Service, scorer *metrics.Scorer, args []string) {
 	if len(args) < 1 {
-		fmt.Println("Usage: project-status <project-id>")
-		os.Exit(1)
+		usage("Usage: project-status <project-id>")
 	}
 
 	projectID := args[0]
 
 	status, err := service.GetProjectStatus(projectID)
 	if err != nil {
-		fmt.Printf("Failed to get project status: %v\n", err)
-		os.Exit(1)
-	}
-
-	fmt.Printf("Project Status:\n")
-	fmt.Printf("ID: %s\n", status.Project.ID)
-	fmt.Printf("Name: %s\n", status.Project.Name)
-	fmt.Printf("State: %s\n", status.Project.State)
-	fmt.Printf("Current Turn: %d/%d\n", status.Project.CurrentTurn, status.Project.MaxTurns)
-	fmt.Printf("Active: %t\n", status.IsActive)
-
-	if status.CurrentDecision != nil {
-		fmt.Printf("\nCurrent Decision:\n")
-		fmt.Printf("ID: %s\n", status.CurrentDecision.ID)
-		fmt.Printf("Description: %s\n", status.CurrentDecision.Description)
-		fmt.Printf("State: %s\n", status.CurrentDecision.State)
-		fmt.Printf("Options: %s\n", strings.Join(status.CurrentDecision.Options, ", "))
-
-		if len(status.VoteCounts) > 0 {
-			fmt.Printf("Vote Counts:\n")
-			for option, count := range status.VoteCounts {
-				fmt.Printf("  %s: %d\n", option, count)
-			}
-		}
+		fail(err, "Failed to get project status")
 	}
 
-	// Show score if project is complete
+	doc := statusDocument{ProjectStatus: status}
 	if status.Project.IsComplete() {
-		score := scorer.CalculateProjectScore(status.Project)
-		if score != nil {
+		doc.Score = scorer.CalculateProjectScore(status.Project)
+	}
+
+	emit(doc, func() {
+		fmt.Printf("Project Status:\n")
+		fmt.Printf("ID: %s\n", status.Project.ID)
+		fmt.Printf("Name: %s\n", status.Project.Name)
+		fmt.Printf("State: %s\n", status.Project.State)
+		fmt.Printf("Version: %d\n", status.Project.Version)
+		if status.Project.CancelReason != "" {
+			fmt.Printf("Cancel Reason: %s\n", status.Project.CancelReason)
+		}
+		fmt.Printf("Current Turn: %d/%d\n", status.Project.CurrentTurn, status.Project.MaxTurns)
+		fmt.Printf("Active: %t\n", status.IsActive)
+
+		if status.CurrentDecision != nil {
+			fmt.Printf("\nCurrent Decision:\n")
+			fmt.Printf("ID: %s\n", status.CurrentDecision.ID)
+			fmt.Printf("Description: %s\n", status.CurrentDecision.Description)
+			fmt.Printf("State: %s\n", status.CurrentDecision.State)
+			fmt.Printf("Options: %s\n", strings.Join(status.CurrentDecision.Options, ", "))
+			if status.CurrentDecision.Deadline != nil {
+				fmt.Printf("Deadline: %s\n", status.CurrentDecision.Deadline.Format(time.RFC3339))
+			}
+
+			if len(status.VoteCounts) > 0 {
+				fmt.Printf("Vote Counts:\n")
+				for _, option := range voteCountOrder(status.CurrentDecision, status.VoteCounts) {
+					fmt.Printf("  %s: %d\n", option, status.VoteCounts[option])
+				}
+			}
+		}
+
+		// Show red-flagged votes for every decision that had any
+		flagged := false
+		for _, decision := range status.Project.Decisions {
+			if decision.FlaggedVotes == 0 {
+				continue
+			}
+			if !flagged {
+				fmt.Printf("\nFlagged Votes:\n")
+				flagged = true
+			}
+			fmt.Printf("  %s: %d\n", decision.ID, decision.FlaggedVotes)
+		}
+
+		// Show score if project is complete
+		if score := doc.Score; score != nil {
 			fmt.Printf("\nFinal Score: %d\n", score.TotalScore)
 			fmt.Printf("Completion Bonus: %d\n", score.CompletionBonus)
 			fmt.Printf("Efficiency Bonus: %d\n", score.EfficiencyBonus)
 			fmt.Printf("Participation Bonus: %d\n", score.ParticipationBonus)
 		}
-	}
+	})
 }
 
-func handleListProjects(service *project.Service, args []string) {
-	projects, err := service.ListProjects()
+func handleReplay(service *project.Service, args []string) {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	until := fs.String("until", "", "stop at this history sequence number or RFC 3339 time")
+	args = parseArgs(fs, args)
+
+	if len(args) < 1 {
+		usage("Usage: replay [--until <seq|time>] <project-id>")
+	}
+
+	var target project.ReplayTarget
+	if *until != "" {
+		if seq, err := strconv.ParseInt(*until, 10, 64); err == nil && seq > 0 {
+			target.Seq = seq
+		} else if parsed, err := time.Parse(time.RFC3339, *until); err == nil {
+			target.Time = parsed
+		} else {
+			fail(invalidArgument(fmt.Errorf("--until %q is neither a positive sequence number nor an RFC 3339 time", *until)), "Invalid --until")
+		}
+	}
+
+	replayed, err := service.ReplayProject(args[0], target)
 	if err != nil {
-		fmt.Printf("Failed to list projects: %v\n", err)
-		os.Exit(1)
+		fail(err, "Failed to replay project")
 	}
 
-	if len(projects) == 0 {
-		fmt.Println("No projects found")
-		return
+	emit(replayed, func() {
+		fmt.Printf("Project %s at history event %d (%s):\n", replayed.ID, replayed.HistorySeq, replayed.UpdatedAt.Format(time.RFC3339))
+		fmt.Printf("State: %s\n", replayed.State)
+		fmt.Printf("Version: %d\n", replayed.Version)
+		fmt.Printf("Current Turn: %d/%d\n", replayed.CurrentTurn, replayed.MaxTurns)
+
+		for i := range replayed.Decisions {
+			decision := &replayed.Decisions[i]
+			fmt.Printf("\n%s (%s): %s\n", decision.ID, decision.State, decision.Description)
+			if decision.Winner != nil {
+				fmt.Printf("Winner: %s\n", *decision.Winner)
+			}
+			for _, option := range voteCountOrder(decision, decision.Votes) {
+				fmt.Printf("  %s: %d\n", option, decision.Votes[option])
+			}
+		}
+	})
+}
+
+// statusDocument is the structured form of project-status
+type statusDocument struct {
+	*project.ProjectStatus
+	Score *metrics.GameScore `json:"score,omitempty"`
+}
+
+// voteCountOrder returns the options with votes in declaration order,
+// followed by any other answers sorted by name
+func voteCountOrder(decision *models.Decision, counts map[string]int) []string {
+	order := make([]string, 0, len(counts))
+	declared := make(map[string]bool, len(decision.Options))
+	for _, option := range decision.Options {
+		declared[option] = true
+		if _, ok := counts[option]; ok {
+			order = append(order, option)
+		}
 	}
 
-	fmt.Printf("Projects:\n")
+	var others []string
+	for option := range counts {
+		if !declared[option] {
+			others = append(others, option)
+		}
+	}
+	sort.Strings(others)
+	return append(order, others...)
+}
+
+func handleListProjects(service *project.Service, args []string) {
+	projects, err := service.ListProjects()
+	if err != nil && !errors.Is(err, project.ErrCorruptProject) {
+		fail(err, "Failed to list projects")
+	}
+	defer exitIfCorrupt(err)
+
+	summaries := make([]projectSummary, 0, len(projects))
 	for _, project := range projects {
-		fmt.Printf("- %s: %s (%s) - Turn %d/%d\n",
-			project.ID, project.Name, project.State, project.CurrentTurn, project.MaxTurns)
+		summaries = append(summaries, projectSummary{
+			ID:          project.ID,
+			Name:        project.Name,
+			State:       project.State,
+			CurrentTurn: project.CurrentTurn,
+			MaxTurns:    project.MaxTurns,
+		})
 	}
+
+	emit(summaries, func() {
+		if len(summaries) == 0 {
+			fmt.Println("No projects found")
+			return
+		}
+
+		fmt.Printf("Projects:\n")
+		for _, project := range summaries {
+			fmt.Printf("- %s: %s (%s) - Turn %d/%d\n",
+				project.ID, project.Name, project.State, project.CurrentTurn, project.MaxTurns)
+		}
+	})
+}
+
+// projectSummary is the structured form of a list-projects entry
+type projectSummary struct {
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	State       models.ProjectState `json:"state"`
+	CurrentTurn int                 `json:"current_turn"`
+	MaxTurns    int                 `json:"max_turns"`
 }
 
 func handleSimulateVoting(service *project.Service, enhancedVoting *voting.EnhancedVotingService, args []string) {
-	if len(args) < 2 {
-		fmt.Println("Usage: simulate-voting <project-id> <decision-id> <agent-count>")
-		os.Exit(1)
+	if len(args) < 3 {
+		usage("Usage: simulate-voting <project-id> <decision-id> <agent-count>")
 	}
 
 	projectID := args[0]
 	decisionID := args[1]
 	agentCount, err := strconv.Atoi(args[2])
 	if err != nil {
-		fmt.Printf("Invalid agent count: %v\n", err)
-		os.Exit(1)
+		fail(invalidArgument(err), "Invalid agent count")
 	}
 
-	// Get the current decision
-	status, err := service.GetProjectStatus(projectID)
+	result, err := service.SimulateVoting(projectID, decisionID, agentCount, voting.SimulationStrategies, enhancedVoting.ChooseVote, preconditions...)
+	if err != nil {
+		fail(err, "Failed to simulate voting")
+	}
+
+	emit(result, func() {
+		fmt.Printf("Simulated %d agents voting\n", result.VotesCast+result.Flagged)
+		if result.Flagged > 0 {
+			fmt.Printf("%d votes were red-flagged\n", result.Flagged)
+		}
+		if result.Resolved {
+			fmt.Printf("Decision %s %s", decisionID, result.Decision.State)
+			if result.Decision.Winner != nil {
+				fmt.Printf(": winner %s", *result.Decision.Winner)
+			}
+			fmt.Println()
+		}
+	})
+}
+
+func handleAdvance(progression *project.ProgressionManager, args []string) {
+	if len(args) < 4 {
+		usage("Usage: advance <project-id> <description> <option1> <option2> [option3...]")
+	}
+
+	projectID := args[0]
+	description := args[1]
+	options := args[2:]
+
+	decision, err := progression.AdvanceProject(projectID, description, options, preconditions...)
 	if err != nil {
-		fmt.Printf("Failed to get project status: %v\n", err)
-		os.Exit(1)
+		fail(err, "Failed to advance project")
 	}
 
-	if status.CurrentDecision == nil || status.CurrentDecision.ID != decisionID {
-		fmt.Println("Decision not found or not active")
-		os.Exit(1)
+	if decision == nil {
+		emitResult(commandResult{
+			Command:   "advance",
+			ProjectID: projectID,
+			Message:   fmt.Sprintf("Project %s completed: maximum turns reached", projectID),
+		})
+		return
 	}
 
-	err = enhancedVoting.SimulateAgentVoting(status.Project, status.CurrentDecision, agentCount)
+	emit(decision, func() {
+		fmt.Printf("Decision ID: %s\n", decision.ID)
+		fmt.Printf("Decision started:\n")
+		printDecision(decision)
+	})
+}
+
+func handleProgress(progression *project.ProgressionManager, args []string) {
+	if len(args) < 1 {
+		usage("Usage: progress <project-id>")
+	}
+
+	progress, err := progression.GetProjectProgress(args[0])
 	if err != nil {
-		fmt.Printf("Failed to simulate voting: %v\n", err)
-		os.Exit(1)
+		fail(err, "Failed to get project progress")
+	}
+
+	emit(progress, func() {
+		fmt.Printf("Project Progress:\n")
+		fmt.Printf("ID: %s\n", progress.ProjectID)
+		fmt.Printf("State: %s\n", progress.State)
+		fmt.Printf("Turn: %d/%d (%.0f%%)\n", progress.CurrentTurn, progress.MaxTurns, progress.ProgressPercentage)
+		fmt.Printf("Decisions: %d completed, %d active\n", progress.CompletedDecisions, progress.ActiveDecisions)
+
+		if len(progress.Decisions) == 0 {
+			return
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer)
+		fmt.Fprintln(writer, "TURN\tID\tSTATE\tWINNER\tVOTES\tCONSENSUS TIME")
+		for _, decision := range progress.Decisions {
+			votes := make([]string, 0, len(decision.Options))
+			for _, option := range decision.Options {
+				votes = append(votes, fmt.Sprintf("%s=%d", option, decision.VoteCounts[option]))
+			}
+
+			winner := decision.Winner
+			if winner == "" {
+				winner = "-"
+			}
+			consensusTime := "-"
+			if decision.ConsensusTime > 0 {
+				consensusTime = decision.ConsensusTime.Round(time.Millisecond).String()
+			}
+
+			fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\n",
+				decision.TurnNumber, decision.ID, decision.State, winner, strings.Join(votes, " "), consensusTime)
+		}
+		writer.Flush()
+	})
+}
+
+func handleConfig(cfg *config.Config, args []string) {
+	emit(cfg, func() {
+		path := cfg.Path
+		if path == "" {
+			path = "(none)"
+		}
+		fmt.Printf("Config File: %s\n", path)
+		fmt.Printf("Data Directory: %s\n", cfg.DataDir)
+		fmt.Printf("Store: %s\n", cfg.Store)
+		fmt.Printf("Output: %s\n", cfg.Output)
+		if cfg.K > 0 {
+			fmt.Printf("Default K: %d\n", cfg.K)
+		}
+		fmt.Printf("Default Max Turns: %d\n", cfg.MaxTurns)
+
+		strategies := make([]string, 0, len(cfg.StrategySeeds))
+		for strategy := range cfg.StrategySeeds {
+			strategies = append(strategies, strategy)
+		}
+		sort.Strings(strategies)
+		for _, strategy := range strategies {
+			fmt.Printf("Seed %s: %d\n", strategy, cfg.StrategySeeds[strategy])
+		}
+	})
+}
+
+func handleServe(service *project.Service, progression *project.ProgressionManager, args []string) {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	parseArgs(fs, args)
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           api.NewServer(service, progression),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	notice("Serving API on http://%s\n", *addr)
+	if err := server.ListenAndServe(); err != nil {
+		fail(err, "Server failed")
+	}
+}
+
+func handleWatch(args []string) {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	addr := fs.String("addr", "localhost:8080", "address of a running voter serve")
+	args = parseArgs(fs, args)
+
+	if len(args) < 1 {
+		usage("Usage: watch [--addr <host:port>] <project-id>")
+	}
+
+	projectID := args[0]
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	baseURL := *addr
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+
+	notice("Watching project %s on %s\n", projectID, baseURL)
+	err := api.Watch(ctx, http.DefaultClient, baseURL, projectID, func(event project.Event) {
+		emitEvent(event)
+		if event.Type == project.EventProjectCompleted {
+			stop()
+		}
+	})
+	if err != nil && ctx.Err() == nil {
+		fail(err, "Failed to watch project")
+	}
+}
+
+// emitEvent writes one event. Structured output is a stream of documents:
+// one JSON object per line, or YAML documents separated by "---".
+func emitEvent(event project.Event) {
+	switch output {
+	case outputText:
+		fmt.Println(formatEvent(event))
+	case outputYAML:
+		fmt.Println("---")
+		if err := writeDocument(os.Stdout, event); err != nil {
+			fail(err, "Failed to encode event")
+		}
+	default:
+		if err := encodeJSON(os.Stdout, event, ""); err != nil {
+			fail(err, "Failed to encode event")
+		}
+	}
+}
+
+// formatEvent renders an event as a single line for watch
+func formatEvent(event project.Event) string {
+	prefix := event.Timestamp.Local().Format("15:04:05")
+
+	switch event.Type {
+	case project.EventDecisionStarted:
+		return fmt.Sprintf("%s %s started", prefix, event.DecisionID)
+	case project.EventVoteCast:
+		options := make([]string, 0, len(event.Votes))
+		for option := range event.Votes {
+			options = append(options, option)
+		}
+		sort.Strings(options)
+		tally := make([]string, 0, len(options))
+		for _, option := range options {
+			tally = append(tally, fmt.Sprintf("%s=%d", option, event.Votes[option]))
+		}
+		return fmt.Sprintf("%s %s: %s voted %s (%s)", prefix, event.DecisionID, event.AgentID, event.Option, strings.Join(tally, ", "))
+	case project.EventDecisionCompleted:
+		line := fmt.Sprintf("%s %s %s", prefix, event.DecisionID, event.State)
+		if event.Winner != nil {
+			line += fmt.Sprintf(": winner %s", *event.Winner)
+		}
+		if event.Resolution != "" {
+			line += fmt.Sprintf(" (%s)", event.Resolution)
+		}
+		if event.Reason != "" {
+			line += fmt.Sprintf(" - %s", event.Reason)
+		}
+		return line
+	case project.EventProjectCompleted:
+		line := fmt.Sprintf("%s project %s %s", prefix, event.ProjectID, event.State)
+		if event.Reason != "" {
+			line += fmt.Sprintf(" - %s", event.Reason)
+		}
+		return line
+	default:
+		return fmt.Sprintf("%s %s", prefix, event.Type)
+	}
+}
+
+func handleSweep(service *project.Service, args []string) {
+	results, err := service.SweepExpiredDecisions()
+	if err != nil && !errors.Is(err, project.ErrCorruptProject) {
+		fail(err, "Failed to sweep decisions")
+	}
+	defer exitIfCorrupt(err)
+
+	if results == nil {
+		results = []project.SweepResult{}
 	}
 
-	fmt.Printf("Simulated %d agents voting\n", agentCount)
+	emit(results, func() {
+		if len(results) == 0 {
+			fmt.Println("No expired decisions")
+			return
+		}
+
+		fmt.Printf("Expired Decisions:\n")
+		for _, result := range results {
+			if result.Extended {
+				fmt.Printf("- %s/%s: extended\n", result.ProjectID, result.DecisionID)
+				continue
+			}
+			fmt.Printf("- %s/%s: %s (%s)\n", result.ProjectID, result.DecisionID, result.State, result.Resolution)
+		}
+	})
 }
 
 func handleProjectStats(tracker *metrics.Tracker, args []string) {
 	stats := tracker.GetGlobalStats()
 
-	fmt.Printf("Global Statistics:\n")
-	fmt.Printf("Total Projects: %d\n", stats.TotalProjects)
-	fmt.Printf("Total Decisions: %d\n", stats.TotalDecisions)
-	fmt.Printf("Average Project Score: %.1f\n", stats.AverageProjectScore)
-	fmt.Printf("Average Consensus Time: %v\n", stats.AverageConsensusTime)
-	if stats.BestProjectID != "" {
-		fmt.Printf("Best Project: %s (Score: %d)\n", stats.BestProjectID, stats.BestProjectScore)
+	emit(stats, func() {
+		fmt.Printf("Global Statistics:\n")
+		fmt.Printf("Total Projects: %d\n", stats.TotalProjects)
+		fmt.Printf("Total Decisions: %d\n", stats.TotalDecisions)
+		fmt.Printf("Average Project Score: %.1f\n", stats.AverageProjectScore)
+		fmt.Printf("Average Consensus Time: %v\n", stats.AverageConsensusTime)
+		if stats.BestProjectID != "" {
+			fmt.Printf("Best Project: %s (Score: %d)\n", stats.BestProjectID, stats.BestProjectScore)
+		}
+	})
+}
+
+func handleRebuildStats(service *project.Service, votes storage.VoteStore, tracker *metrics.Tracker, scorer *metrics.Scorer, args []string) {
+	projects, err := service.ListProjects()
+	if err != nil && !errors.Is(err, project.ErrCorruptProject) {
+		fail(err, "Failed to list projects")
 	}
+	// Corrupt project files are left out of the statistics rather than
+	// stopping the rebuild
+	if err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			notice("Warning: skipping %s\n", line)
+		}
+	}
+
+	if err := tracker.Rebuild(projects, votes, scorer); err != nil {
+		fail(err, "Failed to rebuild statistics")
+	}
+
+	stats := tracker.GetGlobalStats()
+	emit(stats, func() {
+		fmt.Printf("Rebuilt statistics from %d finished projects\n", stats.TotalProjects)
+	})
+}
+
+func handleStrategyStats(tracker *metrics.Tracker, args []string) {
+	performance := tracker.AnalyzeStrategyPerformance()
+	emit(performance, func() {
+		if len(performance) == 0 {
+			fmt.Println("No strategy votes recorded on completed decisions")
+			return
+		}
+
+		strategies := make([]string, 0, len(performance))
+		for strategy := range performance {
+			strategies = append(strategies, strategy)
+		}
+		sort.Strings(strategies)
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "STRATEGY\tVOTES\tAGREED WITH WINNER\tAVG CONSENSUS TIME\tAVG DECISION SCORE")
+		for _, strategy := range strategies {
+			stats := performance[strategy]
+			fmt.Fprintf(writer, "%s\t%d\t%.1f%%\t%v\t%.2f\n",
+				strategy, stats.TotalUses, stats.SuccessRate*100, stats.AverageTime.Round(time.Millisecond), stats.AverageScore)
+		}
+		writer.Flush()
+	})
 }
 
 func handleStrategicVote(service *project.Service, enhancedVoting *voting.EnhancedVotingService, args []string) {
 	if len(args) < 4 {
-		fmt.Println("Usage: strategic-vote <project-id> <decision-id> <agent-id> <strategy>")
-		os.Exit(1)
+		usage("Usage: strategic-vote <project-id> <decision-id> <agent-id> <strategy>")
 	}
 
 	projectID := args[0]
@@ -284,25 +963,135 @@ func handleStrategicVote(service *project.Service, enhancedVoting *voting.Enhanc
 	agentID := args[2]
 	strategy := args[3]
 
-	// Get the current decision
-	status, err := service.GetProjectStatus(projectID)
+	answer, err := service.CastStrategicVote(projectID, decisionID, agentID, strategy, enhancedVoting.ChooseVote, preconditions...)
+	if errors.Is(err, project.ErrDuplicateVote) {
+		failWith(err, fmt.Sprintf("Vote rejected: agent %s has already voted on decision %s", agentID, decisionID))
+	}
 	if err != nil {
-		fmt.Printf("Failed to get project status: %v\n", err)
-		os.Exit(1)
+		fail(err, "Failed to cast strategic vote")
 	}
 
-	if status.CurrentDecision == nil || status.CurrentDecision.ID != decisionID {
-		fmt.Println("Decision not found or not active")
-		os.Exit(1)
+	emitResult(commandResult{
+		Command:    "strategic-vote",
+		ProjectID:  projectID,
+		DecisionID: decisionID,
+		AgentID:    agentID,
+		Option:     answer,
+		Strategy:   strategy,
+		Message:    fmt.Sprintf("Strategic vote cast by agent %s using %s strategy for option '%s'", agentID, strategy, answer),
+	})
+}
+
+// exitIfCorrupt reports corrupt project files after a command has listed
+// the projects that did load, then exits with a failure status
+func exitIfCorrupt(err error) {
+	if err == nil {
+		return
+	}
+	for _, line := range strings.Split(err.Error(), "\n") {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", line)
 	}
+	os.Exit(exitCode(err))
+}
 
-	err = enhancedVoting.CastStrategicVote(status.Project, status.CurrentDecision, agentID, strategy)
-	if err != nil {
-		fmt.Printf("Failed to cast strategic vote: %v\n", err)
-		os.Exit(1)
+// preconditions are applied to every change made by a command, from --if-version
+var preconditions []project.Precondition
+
+// versionedCommands are the commands that change a project and so accept --if-version
+var versionedCommands = map[string]bool{
+	"start-decision":  true,
+	"advance":         true,
+	"vote":            true,
+	"close-voting":    true,
+	"cancel-project":  true,
+	"cancel-decision": true,
+	"pause-project":   true,
+	"resume-project":  true,
+	"simulate-voting": true,
+	"strategic-vote":  true,
+}
+
+// globalFlags are the options accepted before or after any command
+type globalFlags struct {
+	output     string
+	dataDir    string
+	configPath string
+	ifVersion  string
+}
+
+// freeTextCommands end in free text and take no flags of their own, so "--"
+// only marks where text that looks like a flag begins
+var freeTextCommands = map[string]bool{
+	"vote":            true,
+	"cancel-project":  true,
+	"cancel-decision": true,
+}
+
+// extractGlobalFlags removes the global flags from anywhere in the arguments,
+// so they work before or after the command. Arguments after "--" are left
+// alone; for commands ending in free text the "--" itself is dropped.
+func extractGlobalFlags(args []string) (globalFlags, []string, error) {
+	var flags globalFlags
+	targets := map[string]*string{
+		"output":     &flags.output,
+		"o":          &flags.output,
+		"data-dir":   &flags.dataDir,
+		"config":     &flags.configPath,
+		"if-version": &flags.ifVersion,
 	}
 
-	fmt.Printf("Strategic vote cast by agent %s using %s strategy\n", agentID, strategy)
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			if len(rest) > 0 && freeTextCommands[rest[0]] {
+				i++
+			}
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-"), "=")
+		target, ok := targets[name]
+		if !ok || !strings.HasPrefix(arg, "-") {
+			rest = append(rest, arg)
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return globalFlags{}, nil, fmt.Errorf("%s requires a value", arg)
+			}
+			i++
+			value = args[i]
+		}
+		*target = value
+	}
+	return flags, rest, nil
+}
+
+// parseArgs parses flags that may appear anywhere among the positional
+// arguments and returns the positional arguments in order. A bad flag is
+// reported like any other invalid argument, so structured output still gets
+// an error document; -h prints the flag usage and exits.
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	fs.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := fs.Parse(args); errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(os.Stderr)
+			fs.Usage()
+			os.Exit(0)
+		} else if err != nil {
+			fail(invalidArgument(err), fmt.Sprintf("Invalid %s arguments", fs.Name()))
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
 }
 
 func printProject(project *models.Project) {
@@ -319,15 +1108,48 @@ func printUsage() {
 	fmt.Println("Voter - First-to-Ahead-by-K Voting System")
 	fmt.Println()
 	fmt.Println("Commands:")
-	fmt.Println("  create-project <id> <name> <k> [max-turns]    Create a new project")
+	fmt.Println("  create-project [--revote-policy <policy>] [--tie-break <rule>] [--normalize <normalizer>...]")
+	fmt.Println("                 [--max-answer-length <n>] [--require-pattern <regex>] [--require-json]")
+	fmt.Println("                 [--require-json-key <key>...] [--ban-token <text>...]")
+	fmt.Println("                 [--max-votes <n>] [--budget-fallback <action>]")
+	fmt.Println("                 [--decision-timeout <duration>] [--timeout-outcome <action>]")
+	fmt.Println("                 <id> <name> <k> [max-turns]                  Create a new project")
 	fmt.Println("  start-decision <project-id> <desc> <opt1> <opt2> [opt3...]  Start a voting decision")
-	fmt.Println("  vote <project-id> <decision-id> <agent-id> <option>          Cast a vote")
+	fmt.Println("  start-decision --open <project-id> <desc> [candidate...]     Start a decision open to any answer")
+	fmt.Println("                 [--deadline <time>] [--timeout <duration>]  Close voting at a deadline")
+	fmt.Println("                 [--id <decision-id>]                          Use a fixed decision ID")
+	fmt.Println("  vote <project-id> <decision-id> <agent-id> <answer...>       Cast a vote")
 	fmt.Println("  strategic-vote <project-id> <decision-id> <agent-id> <strategy>  Cast strategic vote")
 	fmt.Println("  simulate-voting <project-id> <decision-id> <agent-count>     Simulate agent voting")
 	fmt.Println("  close-voting <project-id>                          Close voting for project")
+	fmt.Println("  cancel-project <project-id> <reason...>            Abort a project without completing it")
+	fmt.Println("  cancel-decision <project-id> <decision-id> <reason...>  Cancel an open decision")
+	fmt.Println("  pause-project <project-id>                         Pause voting and deadline clocks")
+	fmt.Println("  resume-project <project-id>                        Resume a paused project")
 	fmt.Println("  project-status <project-id>                           Show project status")
+	fmt.Println("  advance <project-id> <desc> <opt1> <opt2> [opt3...]          Start the next turn once the last has a winner")
+	fmt.Println("  progress <project-id>                                        Show per-decision progress")
+	fmt.Println("  replay [--until <seq|time>] <project-id>       Rebuild a project's state from its history")
+	fmt.Println("  config                                         Show the effective configuration")
+	fmt.Println("  serve [--addr <host:port>]                     Serve the HTTP API (default localhost:8080)")
+	fmt.Println("  watch [--addr <host:port>] <project-id>        Stream live events from a running server")
+	fmt.Println("  sweep                                          Expire decisions past their deadline")
 	fmt.Println("  list-projects                                  List all projects")
 	fmt.Println("  project-stats                                  Show global statistics")
+	fmt.Println("  rebuild-stats                                  Recompute statistics from stored projects")
+	fmt.Println("  strategy-stats                                 Show how each voting strategy performs")
+	fmt.Println()
+	fmt.Println("Global flags:")
+	fmt.Println("  -o, --output <format>                          Output format: text (default), json or yaml")
+	fmt.Println("  --data-dir <dir>                               Data directory (default ./data, or $VOTER_DATA_DIR)")
+	fmt.Println("  --config <file>                                voter.yaml or voter.toml (default: searched, or $VOTER_CONFIG)")
+	fmt.Println("  --if-version <n>                               Only change the project if it is still at version n")
 	fmt.Println()
+	fmt.Println("Exit codes: 0 success, 1 internal error, 2 usage, 3 not found, 4 conflict, 5 rejected, 6 busy")
 	fmt.Println("Strategies: random, consensus, optimal")
+	fmt.Println("Re-vote policies: reject-duplicate (default), replace-previous, allow-multiple")
+	fmt.Println("Tie-break rules: first-to-reach (default), declaration-order, earliest-vote")
+	fmt.Println("Normalizers: trim, casefold, unicode, json, regex:<pattern>=><replacement>")
+	fmt.Println("Budget fallbacks: plurality (default), cancel, escalate")
+	fmt.Println("Timeout outcomes: plurality (default), cancel, escalate, extend")
 }
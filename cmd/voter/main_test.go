@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// runMainEnv makes the test binary run the CLI instead of the tests
+const runMainEnv = "VOTER_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) != "" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runCLI runs the CLI with args in a scratch directory and returns its stdout
+func runCLI(t *testing.T, args ...string) []byte {
+	t.Helper()
+
+	out, err := cliCommand(t, args...).Output()
+	if err != nil {
+		t.Fatalf("voter %q failed: %v\n%s", args, err, out)
+	}
+	return out
+}
+
+// runCLIFailure runs the CLI with args, expecting it to fail, and returns its
+// stdout and exit code
+func runCLIFailure(t *testing.T, args ...string) ([]byte, int) {
+	t.Helper()
+
+	out, err := cliCommand(t, args...).Output()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Expected voter %q to fail, got %v\n%s", args, err, out)
+	}
+	return out, exitErr.ExitCode()
+}
+
+func cliCommand(t *testing.T, args ...string) *exec.Cmd {
+	dir := t.TempDir()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), runMainEnv+"=1", "HOME="+dir, "XDG_CONFIG_HOME="+dir, "VOTER_CONFIG=", "VOTER_DATA_DIR=")
+	return cmd
+}
+
+func TestGlobalFlagsAfterFreeText(t *testing.T) {
+	dataDir := t.TempDir()
+	runCLI(t, "--data-dir", dataDir, "create-project", "demo", "Demo", "3")
+	runCLI(t, "--data-dir", dataDir, "start-decision", "--open", "demo", "Pick")
+
+	var voted commandResult
+	out := runCLI(t, "--data-dir", dataDir, "vote", "demo", "decision_1", "a1", "hello", "-o", "json")
+	if err := json.Unmarshal(out, &voted); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out, err)
+	}
+	if voted.Option != "hello" {
+		t.Errorf("Expected the answer hello, got %q", voted.Option)
+	}
+
+	var quoted commandResult
+	out = runCLI(t, "--data-dir", dataDir, "-o", "json", "vote", "demo", "decision_1", "a2", "--", "hello", "-o", "json")
+	if err := json.Unmarshal(out, &quoted); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out, err)
+	}
+	if quoted.Option != "hello -o json" {
+		t.Errorf("Expected the answer after -- to be kept, got %q", quoted.Option)
+	}
+
+	var cancelled commandResult
+	out = runCLI(t, "-o", "json", "cancel-project", "demo", "no", "longer", "needed", "--data-dir", dataDir)
+	if err := json.Unmarshal(out, &cancelled); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out, err)
+	}
+	if cancelled.Reason != "no longer needed" {
+		t.Errorf("Expected the reason to exclude --data-dir, got %q", cancelled.Reason)
+	}
+}
+
+func TestBadSubcommandFlag(t *testing.T) {
+	dataDir := t.TempDir()
+
+	tests := [][]string{
+		{"create-project", "--no-such-flag", "demo", "Demo", "3"},
+		{"create-project", "--max-votes", "many", "demo", "Demo", "3"},
+		{"start-decision", "demo", "Pick", "A", "B", "--timeout"},
+		{"replay", "--until"},
+	}
+
+	for _, args := range tests {
+		out, code := runCLIFailure(t, append([]string{"--data-dir", dataDir, "-o", "json"}, args...)...)
+
+		var doc errorDocument
+		if err := json.Unmarshal(out, &doc); err != nil {
+			t.Fatalf("Expected a JSON error document for %q, got %q: %v", args, out, err)
+		}
+		if doc.Error.Code != "invalid_argument" || doc.Error.ExitCode != exitUsage || code != exitUsage {
+			t.Errorf("Expected an invalid_argument error with exit code %d for %q, got %+v (exit %d)", exitUsage, args, doc.Error, code)
+		}
+	}
+}
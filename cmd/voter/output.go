@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/bneil/voter/internal/api"
+	"github.com/bneil/voter/internal/config"
+	"github.com/bneil/voter/internal/storage"
+)
+
+// outputFormat selects how command results and errors are written
+type outputFormat string
+
+const (
+	outputText outputFormat = "text"
+	outputJSON outputFormat = "json"
+	outputYAML outputFormat = "yaml"
+)
+
+// Exit codes. Scripts can rely on these, together with the error codes in
+// structured output.
+const (
+	exitError    = 1 // internal or unclassified failure
+	exitUsage    = 2 // bad command line
+	exitNotFound = 3 // project or decision does not exist
+	exitConflict = 4 // state does not allow the operation
+	exitRejected = 5 // input or vote was rejected
+	exitBusy     = 6 // another process held the project lock too long
+)
+
+var (
+	errUsage           = errors.New("usage")
+	errInvalidArgument = errors.New("invalid argument")
+)
+
+// output is the format selected with --output
+var output = outputText
+
+// parseOutputFormat validates a --output value
+func parseOutputFormat(value string) (outputFormat, error) {
+	switch format := outputFormat(value); format {
+	case outputText, outputJSON, outputYAML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid output format %q: must be text, json or yaml", value)
+	}
+}
+
+// emit writes a command result. Text output is produced by the text function;
+// structured formats serialise v.
+func emit(v interface{}, text func()) {
+	if output == outputText {
+		text()
+		return
+	}
+	if err := writeDocument(os.Stdout, v); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode output: %v\n", err)
+		os.Exit(exitError)
+	}
+}
+
+// writeDocument serialises v in the selected structured format
+func writeDocument(w io.Writer, v interface{}) error {
+	var buf bytes.Buffer
+	if err := encodeJSON(&buf, v, "  "); err != nil {
+		return err
+	}
+
+	if output == outputYAML {
+		doc, err := toYAML(buf.Bytes())
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, doc)
+		return err
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// encodeJSON writes v as JSON followed by a newline, without escaping the
+// HTML characters that appear in usage messages and answers
+func encodeJSON(w io.Writer, v interface{}, indent string) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+	return encoder.Encode(v)
+}
+
+// notice prints an informational line. Structured output keeps stdout for
+// the result document, so notices go to stderr there.
+func notice(format string, args ...interface{}) {
+	if output == outputText {
+		fmt.Printf(format, args...)
+		return
+	}
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
+// commandResult is the structured result of commands that change state
+// without returning a project or decision
+type commandResult struct {
+	Command    string `json:"command"`
+	ProjectID  string `json:"project_id,omitempty"`
+	DecisionID string `json:"decision_id,omitempty"`
+	AgentID    string `json:"agent_id,omitempty"`
+	Option     string `json:"option,omitempty"`
+	Strategy   string `json:"strategy,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Message    string `json:"message"`
+}
+
+// emitResult writes a command result whose text form is its message
+func emitResult(result commandResult) {
+	emit(result, func() {
+		fmt.Println(result.Message)
+	})
+}
+
+// errorDocument is the structured form of a failed command
+type errorDocument struct {
+	Error errorDetail `json:"error"`
+}
+
+// errorDetail describes why a command failed
+type errorDetail struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// argumentError marks a malformed command-line value without changing its message
+type argumentError struct {
+	err error
+}
+
+func (e argumentError) Error() string        { return e.err.Error() }
+func (e argumentError) Unwrap() error        { return e.err }
+func (e argumentError) Is(target error) bool { return target == errInvalidArgument }
+
+// invalidArgument marks err as a bad command-line value
+func invalidArgument(err error) error {
+	return argumentError{err: err}
+}
+
+// errorCode returns the stable code for an error
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, errUsage):
+		return "usage"
+	case errors.Is(err, errInvalidArgument):
+		return "invalid_argument"
+	case errors.Is(err, config.ErrInvalidConfig), errors.Is(err, storage.ErrUnknownBackend):
+		return "invalid_config"
+	default:
+		return api.ErrorCode(err)
+	}
+}
+
+// exitCode returns the process exit code for an error
+func exitCode(err error) int {
+	switch errorCode(err) {
+	case "usage", "invalid_argument", "invalid_config":
+		return exitUsage
+	}
+
+	switch api.StatusCode(err) {
+	case http.StatusNotFound:
+		return exitNotFound
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return exitConflict
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return exitRejected
+	case http.StatusServiceUnavailable:
+		return exitBusy
+	default:
+		return exitError
+	}
+}
+
+// fail reports an error as "<context>: <error>" and exits
+func fail(err error, context string) {
+	failWith(err, fmt.Sprintf("%s: %v", context, err))
+}
+
+// failWith reports an error with a custom text message and exits
+func failWith(err error, message string) {
+	code := exitCode(err)
+	if output == outputText {
+		fmt.Println(message)
+		os.Exit(code)
+	}
+
+	writeDocument(os.Stdout, errorDocument{Error: errorDetail{
+		Code:     errorCode(err),
+		Message:  err.Error(),
+		ExitCode: code,
+	}})
+	os.Exit(code)
+}
+
+// usage reports incorrect command-line usage and exits
+func usage(lines ...string) {
+	message := strings.Join(lines, "\n")
+	failWith(fmt.Errorf("%w: %s", errUsage, strings.TrimSpace(strings.TrimPrefix(lines[0], "Usage:"))), message)
+}
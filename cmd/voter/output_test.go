@@ -0,0 +1,115 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestExtractGlobalFlags(t *testing.T) {
+	tests := []struct {
+		args  []string
+		flags globalFlags
+		rest  []string
+	}{
+		{[]string{"list-projects"}, globalFlags{}, []string{"list-projects"}},
+		{[]string{"--output", "json", "list-projects"}, globalFlags{output: "json"}, []string{"list-projects"}},
+		{[]string{"vote", "p", "-o", "yaml", "d", "a", "B"}, globalFlags{output: "yaml"}, []string{"vote", "p", "d", "a", "B"}},
+		{[]string{"vote", "p", "decision_1", "a1", "hello", "-o", "json"}, globalFlags{output: "json"}, []string{"vote", "p", "decision_1", "a1", "hello"}},
+		{[]string{"cancel-project", "p", "drop", "it", "--data-dir", "x"}, globalFlags{dataDir: "x"}, []string{"cancel-project", "p", "drop", "it"}},
+		{[]string{"vote", "p", "d", "a1", "--", "hello", "-o", "json"}, globalFlags{}, []string{"vote", "p", "d", "a1", "hello", "-o", "json"}},
+		{[]string{"-o", "json", "cancel-decision", "p", "d", "stale"}, globalFlags{output: "json"}, []string{"cancel-decision", "p", "d", "stale"}},
+		{[]string{"project-status", "--output=json", "p"}, globalFlags{output: "json"}, []string{"project-status", "p"}},
+		{[]string{"--data-dir", "/tmp/a", "--config=voter.toml", "sweep"}, globalFlags{dataDir: "/tmp/a", configPath: "voter.toml"}, []string{"sweep"}},
+		{[]string{"serve", "--addr", "localhost:9000"}, globalFlags{}, []string{"serve", "--addr", "localhost:9000"}},
+		{[]string{"simulate-voting", "p", "d", "3", "--", "-o"}, globalFlags{}, []string{"simulate-voting", "p", "d", "3", "--", "-o"}},
+	}
+
+	for _, tt := range tests {
+		flags, rest, err := extractGlobalFlags(tt.args)
+		if err != nil {
+			t.Fatalf("extractGlobalFlags(%q) failed: %v", tt.args, err)
+		}
+		if flags != tt.flags || !reflect.DeepEqual(rest, tt.rest) {
+			t.Errorf("extractGlobalFlags(%q) = %+v %q, expected %+v %q", tt.args, flags, rest, tt.flags, tt.rest)
+		}
+	}
+
+	if _, _, err := extractGlobalFlags([]string{"list-projects", "--output"}); err == nil {
+		t.Error("Expected an error for a flag without a value")
+	}
+}
+
+func TestToYAML(t *testing.T) {
+	input := `{
+		"id": "demo",
+		"k": 2,
+		"active": true,
+		"winner": null,
+		"empty": "",
+		"answer": "yes",
+		"turn": "10",
+		"reason": "tie: no winner",
+		"options": ["A->B", "-1"],
+		"lookalikes": [".inf", "-.Inf", ".nan", "0x1F", "0o17", "0b101", "1_000", "on", "~", "12:30"],
+		"multiline": "line one\nline two",
+		"settings": {},
+		"decisions": [{"id": "decision_1", "votes": {"A": 2}}],
+		"flags": []
+	}`
+
+	expected := `id: demo
+k: 2
+active: true
+winner: null
+empty: ""
+answer: "yes"
+turn: "10"
+reason: "tie: no winner"
+options:
+  - A->B
+  - "-1"
+lookalikes:
+  - ".inf"
+  - "-.Inf"
+  - ".nan"
+  - "0x1F"
+  - "0o17"
+  - "0b101"
+  - "1_000"
+  - "on"
+  - "~"
+  - "12:30"
+multiline: |-
+  line one
+  line two
+settings: {}
+decisions:
+  - id: decision_1
+    votes:
+      A: 2
+flags: []
+`
+
+	got, err := toYAML([]byte(input))
+	if err != nil {
+		t.Fatalf("toYAML failed: %v", err)
+	}
+	if got != expected {
+		t.Errorf("Unexpected YAML:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	// Every string must read back as the same string
+	var doc struct {
+		Lookalikes []interface{} `yaml:"lookalikes"`
+	}
+	if err := yaml.Unmarshal([]byte(got), &doc); err != nil {
+		t.Fatalf("Failed to parse YAML output: %v", err)
+	}
+	for _, value := range doc.Lookalikes {
+		if _, ok := value.(string); !ok {
+			t.Errorf("Expected a string, got %T %v", value, value)
+		}
+	}
+}
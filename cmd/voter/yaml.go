@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// toYAML converts a JSON document to YAML, keeping object keys in the order
+// they were encoded. The YAML encoder decides how each string is quoted.
+func toYAML(data []byte) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	node, err := decodeNode(decoder)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert to YAML: %w", err)
+	}
+
+	var b strings.Builder
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return "", fmt.Errorf("failed to convert to YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("failed to convert to YAML: %w", err)
+	}
+	return b.String(), nil
+}
+
+// decodeNode decodes the next JSON value into a YAML node, preserving
+// object key order
+func decodeNode(decoder *json.Decoder) (*yaml.Node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		var node *yaml.Node
+		switch t {
+		case '{':
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		case '[':
+			node = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		default:
+			return nil, io.ErrUnexpectedEOF
+		}
+		for decoder.More() {
+			if node.Kind == yaml.MappingNode {
+				key, err := decodeNode(decoder)
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, key)
+			}
+			value, err := decodeNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, value)
+		}
+		if len(node.Content) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		_, err := decoder.Token() // closing brace or bracket
+		return node, err
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(t)}, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(t.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: t.String()}, nil
+	case string:
+		// Encoding the string itself applies the encoder's quoting rules,
+		// including those for YAML 1.1 booleans like "yes" and "on"
+		node := &yaml.Node{}
+		if err := node.Encode(t); err != nil {
+			return nil, err
+		}
+		if node.Style&yaml.SingleQuotedStyle != 0 {
+			node.Style = yaml.DoubleQuotedStyle
+		}
+		return node, nil
+	default:
+		return nil, fmt.Errorf("unexpected JSON token %v", t)
+	}
+}
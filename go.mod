@@ -1,3 +1,9 @@
 module github.com/bneil/voter
 
 go 1.23.0
+
+require (
+	github.com/BurntSushi/toml v1.4.0
+	golang.org/x/text v0.28.0
+	gopkg.in/yaml.v3 v3.0.1
+)
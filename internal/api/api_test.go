@@ -0,0 +1,344 @@
+package api_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bneil/voter/internal/api"
+	"github.com/bneil/voter/internal/models"
+	"github.com/bneil/voter/internal/project"
+	"github.com/bneil/voter/internal/storage"
+)
+
+func setupTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	dataDir := t.TempDir()
+	store, err := storage.NewJSONProjectStore(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to create project store: %v", err)
+	}
+	votes, err := storage.NewJSONVoteStore(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to create vote store: %v", err)
+	}
+
+	service := project.NewService(store, votes, project.NewVotingService())
+	server := httptest.NewServer(api.NewServer(service, project.NewProgressionManager(service)))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// request sends a JSON request and decodes the response into out, returning the status code
+func request(t *testing.T, server *httptest.Server, method, path string, body, out interface{}) int {
+	t.Helper()
+
+	var reader bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reader).Encode(body); err != nil {
+			t.Fatalf("Failed to encode body: %v", err)
+		}
+	}
+
+	req, err := http.NewRequest(method, server.URL+path, &reader)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestVotingFlow(t *testing.T) {
+	server := setupTestServer(t)
+
+	var created models.Project
+	status := request(t, server, http.MethodPost, "/projects", api.CreateProjectRequest{ID: "demo", Name: "Demo", K: 2}, &created)
+	if status != http.StatusCreated || created.ID != "demo" {
+		t.Fatalf("Expected project to be created, got %d %+v", status, created)
+	}
+
+	var decision models.Decision
+	status = request(t, server, http.MethodPost, "/projects/demo/decisions",
+		api.StartDecisionRequest{Description: "Pick", Options: []string{"A", "B"}}, &decision)
+	if status != http.StatusCreated || decision.ID != "decision_1" {
+		t.Fatalf("Expected decision_1 to be created, got %d %q", status, decision.ID)
+	}
+
+	for _, agent := range []string{"agent1", "agent2"} {
+		var resp api.CastVoteResponse
+		status = request(t, server, http.MethodPost, "/projects/demo/decisions/decision_1/votes",
+			api.CastVoteRequest{AgentID: agent, Answer: "A"}, &resp)
+		if status != http.StatusOK {
+			t.Fatalf("Expected vote from %s to be accepted, got %d", agent, status)
+		}
+	}
+
+	var projectStatus project.ProjectStatus
+	if status := request(t, server, http.MethodGet, "/projects/demo", nil, &projectStatus); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	winner := projectStatus.Project.Decisions[0].Winner
+	if winner == nil || *winner != "A" {
+		t.Errorf("Expected A to win, got %v", winner)
+	}
+
+	var progress project.ProjectProgress
+	if status := request(t, server, http.MethodGet, "/projects/demo/progress", nil, &progress); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if progress.CompletedDecisions != 1 {
+		t.Errorf("Expected 1 completed decision, got %d", progress.CompletedDecisions)
+	}
+
+	var list api.ListProjectsResponse
+	if status := request(t, server, http.MethodGet, "/projects", nil, &list); status != http.StatusOK || len(list.Projects) != 1 || len(list.Errors) != 0 {
+		t.Errorf("Expected 1 project, got %d %d %v", status, len(list.Projects), list.Errors)
+	}
+}
+
+func TestListProjectsReportsCorruptFiles(t *testing.T) {
+	dataDir := t.TempDir()
+	store, err := storage.NewJSONProjectStore(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to create project store: %v", err)
+	}
+	votes, err := storage.NewJSONVoteStore(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to create vote store: %v", err)
+	}
+	service := project.NewService(store, votes, project.NewVotingService())
+	server := httptest.NewServer(api.NewServer(service, project.NewProgressionManager(service)))
+	t.Cleanup(server.Close)
+
+	request(t, server, http.MethodPost, "/projects", api.CreateProjectRequest{ID: "good", Name: "Good", K: 1}, nil)
+	if err := os.WriteFile(filepath.Join(dataDir, "project_broken.json"), []byte(`{"id": "bro`), 0644); err != nil {
+		t.Fatalf("Failed to write corrupt file: %v", err)
+	}
+
+	// The project that loaded is still listed, next to the corrupt file
+	var list api.ListProjectsResponse
+	if status := request(t, server, http.MethodGet, "/projects", nil, &list); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if len(list.Projects) != 1 || list.Projects[0].ID != "good" {
+		t.Errorf("Expected the good project, got %v", list.Projects)
+	}
+	if len(list.Errors) != 1 || list.Errors[0].Code != "corrupt_project" {
+		t.Errorf("Expected one corrupt_project error, got %v", list.Errors)
+	}
+}
+
+func TestErrorStatusCodes(t *testing.T) {
+	server := setupTestServer(t)
+
+	request(t, server, http.MethodPost, "/projects", api.CreateProjectRequest{ID: "demo", Name: "Demo", K: 1}, nil)
+	request(t, server, http.MethodPost, "/projects/demo/decisions",
+		api.StartDecisionRequest{ID: "d1", Description: "Pick", Options: []string{"A", "B"}}, nil)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		status int
+	}{
+		{"unknown project", http.MethodGet, "/projects/missing", nil, http.StatusNotFound},
+		{"vote on unknown project", http.MethodPost, "/projects/missing/decisions/d1/votes", api.CastVoteRequest{AgentID: "a", Answer: "A"}, http.StatusNotFound},
+		{"unknown decision", http.MethodPost, "/projects/demo/decisions/missing/votes", api.CastVoteRequest{AgentID: "a", Answer: "A"}, http.StatusNotFound},
+		{"duplicate project", http.MethodPost, "/projects", api.CreateProjectRequest{ID: "demo", Name: "Demo", K: 1}, http.StatusConflict},
+		{"decision already active", http.MethodPost, "/projects/demo/decisions", api.StartDecisionRequest{Description: "Next", Options: []string{"A"}}, http.StatusConflict},
+		{"invalid option", http.MethodPost, "/projects/demo/decisions/d1/votes", api.CastVoteRequest{AgentID: "a", Answer: "Z"}, http.StatusUnprocessableEntity},
+		{"missing agent", http.MethodPost, "/projects/demo/decisions/d1/votes", api.CastVoteRequest{Answer: "A"}, http.StatusBadRequest},
+		{"invalid settings", http.MethodPost, "/projects", api.CreateProjectRequest{ID: "bad", Name: "Bad", K: 1, Settings: models.ProjectSettings{RevotePolicy: "sometimes"}}, http.StatusBadRequest},
+		{"unknown field", http.MethodPost, "/projects", map[string]string{"identifier": "x"}, http.StatusBadRequest},
+		{"winning vote", http.MethodPost, "/projects/demo/decisions/d1/votes", api.CastVoteRequest{AgentID: "a", Answer: "A"}, http.StatusOK},
+		{"voting closed", http.MethodPost, "/projects/demo/decisions/d1/votes", api.CastVoteRequest{AgentID: "b", Answer: "A"}, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp map[string]interface{}
+			status := request(t, server, tt.method, tt.path, tt.body, &resp)
+			if status != tt.status {
+				t.Errorf("Expected status %d, got %d (%v)", tt.status, status, resp)
+			}
+			if status >= 400 && resp["error"] == nil {
+				t.Error("Expected an error message")
+			}
+		})
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	server := setupTestServer(t)
+
+	request(t, server, http.MethodPost, "/projects", api.CreateProjectRequest{ID: "demo", Name: "Demo", K: 3}, nil)
+	started, err := server.Client().Post(server.URL+"/projects/demo/decisions", "application/json",
+		strings.NewReader(`{"id": "d1", "description": "Pick", "options": ["A", "B"]}`))
+	if err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+	started.Body.Close()
+	if etag := started.Header.Get("ETag"); started.StatusCode != http.StatusCreated || etag != `"2"` {
+		t.Fatalf("Expected the decision to start with ETag \"2\", got %d %s", started.StatusCode, etag)
+	}
+
+	resp, err := server.Client().Get(server.URL + "/projects/demo")
+	if err != nil {
+		t.Fatalf("Failed to get project: %v", err)
+	}
+	resp.Body.Close()
+	etag := resp.Header.Get("ETag")
+	if etag != `"2"` {
+		t.Fatalf("Expected ETag \"2\" after two saves, got %s", etag)
+	}
+
+	vote := func(agentID, ifMatch string) *http.Response {
+		t.Helper()
+		body := strings.NewReader(`{"agent_id": "` + agentID + `", "answer": "A"}`)
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/projects/demo/decisions/d1/votes", body)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("If-Match", ifMatch)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	resp = vote("agent1", etag)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") != `"3"` {
+		t.Fatalf("Expected the vote to succeed with ETag \"3\", got %d %s", resp.StatusCode, resp.Header.Get("ETag"))
+	}
+
+	// The ETag is now stale
+	if resp := vote("agent2", etag); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("Expected status 412 for a stale If-Match, got %d", resp.StatusCode)
+	}
+	if resp := vote("agent2", "latest"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a malformed If-Match, got %d", resp.StatusCode)
+	}
+	if resp := vote("agent2", "*"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected If-Match * to match any version, got %d", resp.StatusCode)
+	}
+}
+
+func TestAdvanceETag(t *testing.T) {
+	server := setupTestServer(t)
+
+	request(t, server, http.MethodPost, "/projects", api.CreateProjectRequest{ID: "demo", Name: "Demo", K: 1, MaxTurns: 1}, nil)
+
+	advance := func(ifMatch string) *http.Response {
+		t.Helper()
+		body := strings.NewReader(`{"description": "Pick", "options": ["A", "B"]}`)
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/projects/demo/advance", body)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := advance("")
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("ETag") != `"2"` {
+		t.Fatalf("Expected advancing to start a decision with ETag \"2\", got %d %s", resp.StatusCode, resp.Header.Get("ETag"))
+	}
+	vote := api.CastVoteRequest{AgentID: "agent1", Answer: "A"}
+	if status := request(t, server, http.MethodPost, "/projects/demo/decisions/decision_1/votes", vote, nil); status != http.StatusOK {
+		t.Fatalf("Failed to cast vote: %d", status)
+	}
+
+	// The ETag of the completing advance can be chained into the next change
+	resp = advance(`"3"`)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") != `"4"` {
+		t.Fatalf("Expected advancing to complete the project with ETag \"4\", got %d %s", resp.StatusCode, resp.Header.Get("ETag"))
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	server := setupTestServer(t)
+
+	request(t, server, http.MethodPost, "/projects", api.CreateProjectRequest{ID: "demo", Name: "Demo", K: 1}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The subscription exists once the response headers arrive
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/projects/demo/events", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open event stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected text/event-stream, got %q", ct)
+	}
+
+	request(t, server, http.MethodPost, "/projects/demo/decisions",
+		api.StartDecisionRequest{ID: "d1", Description: "Pick", Options: []string{"A", "B"}}, nil)
+	request(t, server, http.MethodPost, "/projects/demo/decisions/d1/votes", api.CastVoteRequest{AgentID: "agent1", Answer: "A"}, nil)
+
+	scanner := bufio.NewScanner(resp.Body)
+	expected := []project.EventType{project.EventDecisionStarted, project.EventVoteCast, project.EventDecisionCompleted}
+	for _, eventType := range expected {
+		var name string
+		var event project.Event
+		for scanner.Scan() {
+			line := scanner.Text()
+			if after, ok := strings.CutPrefix(line, "event: "); ok {
+				name = after
+			}
+			if after, ok := strings.CutPrefix(line, "data: "); ok {
+				if err := json.Unmarshal([]byte(after), &event); err != nil {
+					t.Fatalf("Failed to decode event: %v", err)
+				}
+				break
+			}
+		}
+		if name != string(eventType) || event.Type != eventType {
+			t.Fatalf("Expected %s event, got %q/%s (%v)", eventType, name, event.Type, scanner.Err())
+		}
+	}
+}
+
+func TestEventStreamUnknownProject(t *testing.T) {
+	server := setupTestServer(t)
+
+	err := api.Watch(context.Background(), server.Client(), server.URL, "missing", func(project.Event) {})
+	if err == nil {
+		t.Fatal("Expected an error for an unknown project")
+	}
+}
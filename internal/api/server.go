@@ -0,0 +1,470 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bneil/voter/internal/models"
+	"github.com/bneil/voter/internal/project"
+)
+
+// maxBodySize limits the size of request bodies
+const maxBodySize = 1 << 20
+
+var (
+	ErrInvalidRequest = errors.New("invalid request")
+)
+
+// Server exposes the project service over HTTP/JSON
+type Server struct {
+	service     *project.Service
+	progression *project.ProgressionManager
+	mux         *http.ServeMux
+}
+
+// NewServer creates a new API server backed by the given project service
+func NewServer(service *project.Service, progression *project.ProgressionManager) *Server {
+	s := &Server{
+		service:     service,
+		progression: progression,
+		mux:         http.NewServeMux(),
+	}
+	s.routes()
+	return s
+}
+
+// routes registers the API endpoints
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /projects", s.handleListProjects)
+	s.mux.HandleFunc("POST /projects", s.handleCreateProject)
+	s.mux.HandleFunc("GET /projects/{id}", s.handleProjectStatus)
+	s.mux.HandleFunc("GET /projects/{id}/progress", s.handleProgress)
+	s.mux.HandleFunc("GET /projects/{id}/events", s.handleEvents)
+	s.mux.HandleFunc("POST /projects/{id}/decisions", s.handleStartDecision)
+	s.mux.HandleFunc("POST /projects/{id}/decisions/{decision}/votes", s.handleCastVote)
+	s.mux.HandleFunc("POST /projects/{id}/decisions/{decision}/cancel", s.handleCancelDecision)
+	s.mux.HandleFunc("POST /projects/{id}/advance", s.handleAdvance)
+	s.mux.HandleFunc("POST /projects/{id}/pause", s.handlePauseProject)
+	s.mux.HandleFunc("POST /projects/{id}/resume", s.handleResumeProject)
+	s.mux.HandleFunc("POST /projects/{id}/end", s.handleEndProject)
+	s.mux.HandleFunc("POST /projects/{id}/cancel", s.handleCancelProject)
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// CreateProjectRequest is the body of POST /projects
+type CreateProjectRequest struct {
+	ID       string                 `json:"id"`
+	Name     string                 `json:"name"`
+	K        int                    `json:"k"`
+	MaxTurns int                    `json:"max_turns"`
+	Settings models.ProjectSettings `json:"settings"`
+}
+
+// StartDecisionRequest is the body of POST /projects/{id}/decisions
+type StartDecisionRequest struct {
+	ID          string     `json:"id,omitempty"` // generated from the turn number when empty
+	Description string     `json:"description"`
+	Options     []string   `json:"options"`
+	Open        bool       `json:"open,omitempty"`
+	Deadline    *time.Time `json:"deadline,omitempty"`
+	Timeout     string     `json:"timeout,omitempty"` // Go duration, e.g. "30s"
+}
+
+// Validate checks the request and fills in defaults
+func (r *CreateProjectRequest) Validate() error {
+	if r.ID == "" || r.Name == "" {
+		return fmt.Errorf("%w: id and name are required", ErrInvalidRequest)
+	}
+	if r.K < 1 {
+		return fmt.Errorf("%w: k must be at least 1", ErrInvalidRequest)
+	}
+	if r.MaxTurns == 0 {
+		r.MaxTurns = 10 // same default as the CLI
+	}
+	return nil
+}
+
+// Config converts the request into the service's decision configuration
+func (r StartDecisionRequest) Config() (project.DecisionConfig, error) {
+	config := project.DecisionConfig{Open: r.Open}
+	if r.Deadline != nil {
+		config.Deadline = *r.Deadline
+	}
+	if r.Timeout != "" {
+		timeout, err := time.ParseDuration(r.Timeout)
+		if err != nil || timeout <= 0 {
+			return config, fmt.Errorf("%w: invalid timeout %q", ErrInvalidRequest, r.Timeout)
+		}
+		config.Timeout = timeout
+	}
+	return config, nil
+}
+
+// CastVoteRequest is the body of POST /projects/{id}/decisions/{decision}/votes
+type CastVoteRequest struct {
+	AgentID string `json:"agent_id"`
+	Answer  string `json:"answer"`
+}
+
+// CastVoteResponse reports the state of the decision after a vote
+type CastVoteResponse struct {
+	Decision *models.Decision `json:"decision"`
+}
+
+// ListProjectsResponse is the body of GET /projects. Project files that could
+// not be loaded are listed in Errors; the projects that did load are still returned.
+type ListProjectsResponse struct {
+	Projects []*models.Project `json:"projects"`
+	Errors   []ErrorResponse   `json:"errors,omitempty"`
+}
+
+// CancelRequest is the body of POST /projects/{id}/cancel and
+// POST /projects/{id}/decisions/{decision}/cancel
+type CancelRequest struct {
+	Reason string `json:"reason"`
+}
+
+// AdvanceRequest is the body of POST /projects/{id}/advance
+type AdvanceRequest struct {
+	Description string   `json:"description"`
+	Options     []string `json:"options"`
+}
+
+// AdvanceResponse reports the decision started by advancing a project, or
+// that the project was completed instead
+type AdvanceResponse struct {
+	Decision  *models.Decision `json:"decision,omitempty"`
+	Completed bool             `json:"completed"`
+}
+
+// ErrorResponse is the body of every error reply
+type ErrorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+func (s *Server) handleListProjects(w http.ResponseWriter, r *http.Request) {
+	projects, err := s.service.ListProjects()
+	if err != nil && !errors.Is(err, project.ErrCorruptProject) {
+		writeError(w, err)
+		return
+	}
+
+	resp := ListProjectsResponse{Projects: projects}
+	if resp.Projects == nil {
+		resp.Projects = []*models.Project{}
+	}
+	// Corrupt project files are reported alongside the projects that loaded
+	if err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			resp.Errors = append(resp.Errors, ErrorResponse{Error: line, Code: ErrorCode(project.ErrCorruptProject)})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleCreateProject(w http.ResponseWriter, r *http.Request) {
+	var req CreateProjectRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	p, err := s.service.CreateProjectWithSettings(req.ID, req.Name, req.K, req.MaxTurns, req.Settings)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, p.Version)
+	writeJSON(w, http.StatusCreated, p)
+}
+
+func (s *Server) handleProjectStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.service.GetProjectStatus(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, status.Project.Version)
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) handleProgress(w http.ResponseWriter, r *http.Request) {
+	progress, err := s.progression.GetProjectProgress(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, progress)
+}
+
+func (s *Server) handleStartDecision(w http.ResponseWriter, r *http.Request) {
+	var req StartDecisionRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	config, err := req.Config()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	preconditions, err := ifMatch(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	p, decision, err := s.service.StartDecisionWithConfig(r.PathValue("id"), req.ID, req.Description, req.Options, config, preconditions...)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, p.Version)
+	writeJSON(w, http.StatusCreated, decision)
+}
+
+func (s *Server) handleCastVote(w http.ResponseWriter, r *http.Request) {
+	var req CastVoteRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if req.AgentID == "" {
+		writeError(w, fmt.Errorf("%w: agent_id is required", ErrInvalidRequest))
+		return
+	}
+
+	preconditions, err := ifMatch(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	projectID := r.PathValue("id")
+	decisionID := r.PathValue("decision")
+	p, err := s.service.CastVote(projectID, decisionID, req.AgentID, req.Answer, preconditions...)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, p.Version)
+	writeJSON(w, http.StatusOK, CastVoteResponse{Decision: p.FindDecision(decisionID)})
+}
+
+func (s *Server) handleCancelDecision(w http.ResponseWriter, r *http.Request) {
+	var req CancelRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	preconditions, err := ifMatch(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.service.CancelDecision(r.PathValue("id"), r.PathValue("decision"), req.Reason, preconditions...); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleAdvance(w http.ResponseWriter, r *http.Request) {
+	var req AdvanceRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	preconditions, err := ifMatch(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	p, decision, err := s.progression.AdvanceProjectWithResult(r.PathValue("id"), req.Description, req.Options, preconditions...)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, p.Version)
+	if decision == nil {
+		writeJSON(w, http.StatusOK, AdvanceResponse{Completed: true})
+		return
+	}
+	writeJSON(w, http.StatusCreated, AdvanceResponse{Decision: decision})
+}
+
+func (s *Server) handlePauseProject(w http.ResponseWriter, r *http.Request) {
+	s.handleLifecycle(w, r, s.service.PauseProject)
+}
+
+func (s *Server) handleResumeProject(w http.ResponseWriter, r *http.Request) {
+	s.handleLifecycle(w, r, s.service.ResumeProject)
+}
+
+func (s *Server) handleEndProject(w http.ResponseWriter, r *http.Request) {
+	s.handleLifecycle(w, r, s.service.EndProject)
+}
+
+func (s *Server) handleCancelProject(w http.ResponseWriter, r *http.Request) {
+	var req CancelRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	s.handleLifecycle(w, r, func(projectID string, preconditions ...project.Precondition) error {
+		return s.service.CancelProject(projectID, req.Reason, preconditions...)
+	})
+}
+
+// handleLifecycle applies a change to a project's state and replies 204 No Content
+func (s *Server) handleLifecycle(w http.ResponseWriter, r *http.Request, change func(projectID string, preconditions ...project.Precondition) error) {
+	preconditions, err := ifMatch(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := change(r.PathValue("id"), preconditions...); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ifMatch turns an If-Match header holding a project version, as sent in the
+// ETag of a project response, into a precondition. A missing header or "*"
+// matches any version.
+func ifMatch(r *http.Request) ([]project.Precondition, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+	if err != nil || version < 1 {
+		return nil, fmt.Errorf("%w: If-Match must be a project version, got %s", ErrInvalidRequest, value)
+	}
+	return []project.Precondition{project.IfVersion(version)}, nil
+}
+
+// setETag reports a project's version in the ETag header, for use in If-Match
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// decodeJSON decodes a request body, rejecting unknown fields
+func decodeJSON(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	return nil
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err as a JSON error response
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, StatusCode(err), ErrorResponse{Error: err.Error(), Code: ErrorCode(err)})
+}
+
+// errorKinds maps service errors to the stable codes and HTTP statuses they
+// are reported with. Codes are part of the API and must not change.
+var errorKinds = []struct {
+	err    error
+	code   string
+	status int
+}{
+	{project.ErrProjectNotFound, "project_not_found", http.StatusNotFound},
+	{project.ErrDecisionNotFound, "decision_not_found", http.StatusNotFound},
+	{project.ErrProjectExists, "project_exists", http.StatusConflict},
+	{project.ErrDuplicateDecision, "duplicate_decision", http.StatusConflict},
+	{project.ErrDuplicateVote, "duplicate_vote", http.StatusConflict},
+	{project.ErrDecisionActive, "decision_active", http.StatusConflict},
+	{project.ErrNoWinner, "no_winner", http.StatusConflict},
+	{project.ErrProjectPaused, "project_paused", http.StatusConflict},
+	{project.ErrProjectNotPaused, "project_not_paused", http.StatusConflict},
+	{project.ErrProjectNotActive, "project_not_active", http.StatusConflict},
+	{project.ErrVotingClosed, "voting_closed", http.StatusConflict},
+	{project.ErrVoteFlagged, "vote_flagged", http.StatusUnprocessableEntity},
+	{project.ErrInvalidOption, "invalid_option", http.StatusUnprocessableEntity},
+	{project.ErrInvalidVote, "invalid_vote", http.StatusUnprocessableEntity},
+	{project.ErrInvalidDecision, "invalid_decision", http.StatusBadRequest},
+	{project.ErrInvalidSettings, "invalid_settings", http.StatusBadRequest},
+	{project.ErrReasonRequired, "reason_required", http.StatusBadRequest},
+	{ErrInvalidRequest, "invalid_request", http.StatusBadRequest},
+	{project.ErrCorruptProject, "corrupt_project", http.StatusInternalServerError},
+	{project.ErrLockTimeout, "lock_timeout", http.StatusServiceUnavailable},
+	{project.ErrVersionConflict, "version_conflict", http.StatusPreconditionFailed},
+	{project.ErrNoHistory, "no_history", http.StatusNotFound},
+	{project.ErrInvalidHistory, "invalid_history", http.StatusInternalServerError},
+}
+
+// ErrorCode returns the stable code for a service error, or "internal" for
+// errors without one
+func ErrorCode(err error) string {
+	for _, kind := range errorKinds {
+		if errors.Is(err, kind.err) {
+			return kind.code
+		}
+	}
+	return "internal"
+}
+
+// ErrorForCode returns the sentinel error for a code, or nil if the code is unknown
+func ErrorForCode(code string) error {
+	for _, kind := range errorKinds {
+		if kind.code == code {
+			return kind.err
+		}
+	}
+	return nil
+}
+
+// StatusCode maps a service error to the HTTP status code it is reported with
+func StatusCode(err error) int {
+	for _, kind := range errorKinds {
+		if errors.Is(err, kind.err) {
+			return kind.status
+		}
+	}
+	return http.StatusInternalServerError
+}
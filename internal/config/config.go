@@ -0,0 +1,208 @@
+// Package config loads CLI defaults from a voter.yaml or voter.toml file.
+//
+// Unknown keys and values of the wrong type are rejected.
+//
+//	data_dir: ./data
+//	store: json
+//	output: text
+//	k: 3
+//	max_turns: 10
+//	strategy_seeds:
+//	  random: 42
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables read by the CLI
+const (
+	EnvConfig  = "VOTER_CONFIG"
+	EnvDataDir = "VOTER_DATA_DIR"
+)
+
+// DefaultDataDir is used when no data directory is configured
+const DefaultDataDir = "./data"
+
+// FileNames are the config files searched for, in order, in the working
+// directory and then in the user config directory under "voter"
+var FileNames = []string{"voter.yaml", "voter.yml", "voter.toml"}
+
+// ErrInvalidConfig is returned for config files that cannot be parsed or hold bad values
+var ErrInvalidConfig = errors.New("invalid config")
+
+// Config holds defaults for the CLI
+type Config struct {
+	// Path is the file the config was loaded from, or empty for defaults
+	Path string `json:"path,omitempty"`
+
+	DataDir       string           `json:"data_dir"`
+	Store         string           `json:"store"`
+	Output        string           `json:"output,omitempty"`
+	K             int              `json:"k,omitempty"`
+	MaxTurns      int              `json:"max_turns"`
+	StrategySeeds map[string]int64 `json:"strategy_seeds,omitempty"`
+}
+
+// fileConfig is the layout of a config file. Pointers tell settings that
+// are missing from the file apart from zero values.
+type fileConfig struct {
+	DataDir       *string          `yaml:"data_dir" toml:"data_dir"`
+	Store         *string          `yaml:"store" toml:"store"`
+	Output        *string          `yaml:"output" toml:"output"`
+	K             *int             `yaml:"k" toml:"k"`
+	MaxTurns      *int             `yaml:"max_turns" toml:"max_turns"`
+	StrategySeeds map[string]int64 `yaml:"strategy_seeds" toml:"strategy_seeds"`
+}
+
+// Default returns the built-in configuration
+func Default() *Config {
+	return &Config{
+		DataDir:  DefaultDataDir,
+		Store:    "json",
+		MaxTurns: 10,
+	}
+}
+
+// Find returns the config file to load. An explicit path wins, then
+// VOTER_CONFIG, then the first of FileNames found in the working directory or
+// the user config directory. It returns "" when there is no config file.
+func Find(explicit string) (string, error) {
+	if explicit == "" {
+		explicit = os.Getenv(EnvConfig)
+	}
+	if explicit != "" {
+		if _, err := os.Stat(explicit); err != nil {
+			return "", fmt.Errorf("failed to read config: %w", err)
+		}
+		return explicit, nil
+	}
+
+	dirs := []string{"."}
+	if userDir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(userDir, "voter"))
+	}
+	for _, dir := range dirs {
+		for _, name := range FileNames {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// Load reads a config file over the defaults. The format is chosen by the
+// file extension. A relative data_dir is resolved against the file's directory.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var file fileConfig
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = decodeYAML(data, &file)
+	case ".toml":
+		err = decodeTOML(data, &file)
+	default:
+		return nil, fmt.Errorf("%w: unsupported config format %q", ErrInvalidConfig, ext)
+	}
+	if err == nil {
+		err = file.validate()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	file.apply(cfg)
+	if file.DataDir != nil && cfg.DataDir != "" && !filepath.IsAbs(cfg.DataDir) {
+		cfg.DataDir = filepath.Join(filepath.Dir(path), cfg.DataDir)
+	}
+
+	cfg.Path = path
+	return cfg, nil
+}
+
+// ResolveDataDir returns the data directory to use: the flag value if set,
+// then VOTER_DATA_DIR, then the config file
+func (c *Config) ResolveDataDir(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if env := os.Getenv(EnvDataDir); env != "" {
+		return env
+	}
+	return c.DataDir
+}
+
+// validate checks the values read from a config file
+func (f *fileConfig) validate() error {
+	if f.K != nil && *f.K < 1 {
+		return fmt.Errorf("%w: k: must be at least 1, got %d", ErrInvalidConfig, *f.K)
+	}
+	if f.MaxTurns != nil && *f.MaxTurns < 1 {
+		return fmt.Errorf("%w: max_turns: must be at least 1, got %d", ErrInvalidConfig, *f.MaxTurns)
+	}
+	return nil
+}
+
+// apply sets the fields present in the file
+func (f *fileConfig) apply(c *Config) {
+	if f.DataDir != nil {
+		c.DataDir = *f.DataDir
+	}
+	if f.Store != nil {
+		c.Store = *f.Store
+	}
+	if f.Output != nil {
+		c.Output = *f.Output
+	}
+	if f.K != nil {
+		c.K = *f.K
+	}
+	if f.MaxTurns != nil {
+		c.MaxTurns = *f.MaxTurns
+	}
+	if len(f.StrategySeeds) > 0 {
+		c.StrategySeeds = f.StrategySeeds
+	}
+}
+
+// decodeYAML decodes a YAML config, rejecting unknown keys
+func decodeYAML(data []byte, f *fileConfig) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(f); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	return nil
+}
+
+// decodeTOML decodes a TOML config, rejecting unknown keys
+func decodeTOML(data []byte, f *fileConfig) error {
+	meta, err := toml.Decode(string(data), f)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return fmt.Errorf("%w: unknown key %s", ErrInvalidConfig, undecoded[0])
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bneil/voter/internal/config"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	files := map[string]string{
+		"voter.yaml": `# defaults for the demo environment
+data_dir: demo-data
+store: json
+output: "json"
+k: 3
+max_turns: 20 # longer games
+strategy_seeds:
+  random: 42
+  consensus: 7
+`,
+		"voter.toml": `# defaults for the demo environment
+data_dir = "demo-data"
+store = "json"
+output = 'json'
+k = 3
+max_turns = 20 # longer games
+
+[strategy_seeds]
+random = 42
+consensus = 7
+`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := writeConfig(t, name, content)
+
+			cfg, err := config.Load(path)
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+
+			expected := &config.Config{
+				Path:          path,
+				DataDir:       filepath.Join(filepath.Dir(path), "demo-data"),
+				Store:         "json",
+				Output:        "json",
+				K:             3,
+				MaxTurns:      20,
+				StrategySeeds: map[string]int64{"random": 42, "consensus": 7},
+			}
+			if !reflect.DeepEqual(cfg, expected) {
+				t.Errorf("Expected %+v, got %+v", expected, cfg)
+			}
+		})
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := config.Load("")
+	if err != nil {
+		t.Fatalf("Failed to load defaults: %v", err)
+	}
+	if cfg.DataDir != config.DefaultDataDir || cfg.Store != "json" || cfg.MaxTurns != 10 || cfg.K != 0 {
+		t.Errorf("Unexpected defaults: %+v", cfg)
+	}
+
+	// Settings missing from a file keep their defaults
+	cfg, err = config.Load(writeConfig(t, "voter.yaml", "k: 2\n"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.K != 2 || cfg.MaxTurns != 10 || cfg.DataDir != config.DefaultDataDir {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"unknown key", "voter.yaml", "colour: blue\n"},
+		{"bad number", "voter.yaml", "k: three\n"},
+		{"zero max turns", "voter.yaml", "max_turns: 0\n"},
+		{"negative k", "voter.yaml", "k: -1\n"},
+		{"bad seed", "voter.yaml", "strategy_seeds:\n  random: lucky\n"},
+		{"unknown nested key", "voter.yaml", "strategy_seeds:\n  random:\n    seed: 1\n"},
+		{"bad indentation", "voter.yaml", "k: 3\n  max_turns: 2\n"},
+		{"missing colon", "voter.yaml", "k 3\n"},
+		{"unterminated quote", "voter.yaml", "data_dir: \"demo\n"},
+		{"tab indentation", "voter.yaml", "strategy_seeds:\n\trandom: 42\n"},
+		{"duplicate key", "voter.yaml", "k: 2\nk: 3\n"},
+		{"not a mapping", "voter.yaml", "- k\n- 3\n"},
+		{"toml unknown key", "voter.toml", "colour = \"blue\"\n"},
+		{"toml unknown table", "voter.toml", "[colours]\nsky = \"blue\"\n"},
+		{"toml bad number", "voter.toml", "k = \"three\"\n"},
+		{"toml zero max turns", "voter.toml", "max_turns = 0\n"},
+		{"toml bad seed", "voter.toml", "[strategy_seeds]\nrandom = \"lucky\"\n"},
+		{"toml unterminated table", "voter.toml", "[strategy_seeds\nrandom = 42\n"},
+		{"toml unterminated string", "voter.toml", "data_dir = \"demo\n"},
+		{"toml missing equals", "voter.toml", "k 3\n"},
+		{"toml duplicate key", "voter.toml", "k = 2\nk = 3\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := config.Load(writeConfig(t, tt.file, tt.content)); !errors.Is(err, config.ErrInvalidConfig) {
+				t.Errorf("Expected ErrInvalidConfig, got %v", err)
+			}
+		})
+	}
+
+	if _, err := config.Load(writeConfig(t, "voter.ini", "k = 3\n")); !errors.Is(err, config.ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig for an unsupported format, got %v", err)
+	}
+}
+
+func TestResolveDataDir(t *testing.T) {
+	cfg := config.Default()
+
+	t.Setenv(config.EnvDataDir, "")
+	if dir := cfg.ResolveDataDir(""); dir != config.DefaultDataDir {
+		t.Errorf("Expected the config data dir, got %s", dir)
+	}
+
+	t.Setenv(config.EnvDataDir, "/env/data")
+	if dir := cfg.ResolveDataDir(""); dir != "/env/data" {
+		t.Errorf("Expected VOTER_DATA_DIR to override the config, got %s", dir)
+	}
+	if dir := cfg.ResolveDataDir("/flag/data"); dir != "/flag/data" {
+		t.Errorf("Expected --data-dir to override VOTER_DATA_DIR, got %s", dir)
+	}
+}
+
+func TestFind(t *testing.T) {
+	path := writeConfig(t, "custom.toml", "k = 2\n")
+
+	found, err := config.Find(path)
+	if err != nil || found != path {
+		t.Errorf("Expected explicit path %s, got %s (%v)", path, found, err)
+	}
+
+	t.Setenv(config.EnvConfig, path)
+	found, err = config.Find("")
+	if err != nil || found != path {
+		t.Errorf("Expected VOTER_CONFIG path %s, got %s (%v)", path, found, err)
+	}
+
+	if _, err := config.Find(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing explicit config file")
+	}
+}
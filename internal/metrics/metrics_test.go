@@ -0,0 +1,275 @@
+package metrics_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bneil/voter/internal/metrics"
+	"github.com/bneil/voter/internal/models"
+	"github.com/bneil/voter/internal/storage"
+)
+
+func completedProject(id string, decisions int) *models.Project {
+	p := models.NewProject(id, "Test Project", 1, 10)
+	for i := 0; i < decisions; i++ {
+		decision := models.NewDecision(p.ID+"-decision", p.ID, "Pick", i+1, []string{"A", "B"})
+		decision.AddVote("A")
+		winner := "A"
+		completed := decision.VotingStarted.Add(10 * time.Second)
+		decision.Winner = &winner
+		decision.State = models.DecisionStateCompleted
+		decision.CompletedAt = &completed
+		p.Decisions = append(p.Decisions, *decision)
+	}
+	p.Metrics.TotalDecisions = decisions
+	p.Metrics.TotalVotes = decisions
+	p.State = models.ProjectStateCompleted
+	return p
+}
+
+func TestTrackerPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.json")
+	scorer := metrics.NewScorer()
+
+	tracker, err := metrics.LoadTracker(path)
+	if err != nil {
+		t.Fatalf("Failed to load tracker: %v", err)
+	}
+
+	project := completedProject("alpha", 2)
+	if err := tracker.RecordProject(project, scorer); err != nil {
+		t.Fatalf("Failed to record project: %v", err)
+	}
+	// Recording the same project again must not count it twice
+	if err := tracker.RecordProject(project, scorer); err != nil {
+		t.Fatalf("Failed to record project: %v", err)
+	}
+
+	reloaded, err := metrics.LoadTracker(path)
+	if err != nil {
+		t.Fatalf("Failed to reload tracker: %v", err)
+	}
+
+	stats := reloaded.GetGlobalStats()
+	if stats.TotalProjects != 1 || stats.TotalDecisions != 2 {
+		t.Errorf("Expected 1 project and 2 decisions, got %d and %d", stats.TotalProjects, stats.TotalDecisions)
+	}
+	if stats.BestProjectID != "alpha" {
+		t.Errorf("Expected best project alpha, got %q", stats.BestProjectID)
+	}
+	if reloaded.GetProjectScore("alpha") == nil {
+		t.Error("Expected project score to be persisted")
+	}
+	if reloaded.GetDecisionScore(metrics.DecisionKey("alpha", "alpha-decision")) == nil {
+		t.Error("Expected decision score to be persisted")
+	}
+}
+
+func TestConcurrentTrackersMerge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.json")
+	scorer := metrics.NewScorer()
+
+	// Each tracker stands in for a separate voter process that loaded the
+	// file before any of the others recorded anything
+	const writers = 20
+	trackers := make([]*metrics.Tracker, writers)
+	for i := range trackers {
+		tracker, err := metrics.LoadTracker(path)
+		if err != nil {
+			t.Fatalf("Failed to load tracker: %v", err)
+		}
+		trackers[i] = tracker
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i, tracker := range trackers {
+		wg.Add(1)
+		go func(i int, tracker *metrics.Tracker) {
+			defer wg.Done()
+			project := completedProject(fmt.Sprintf("project_%d", i), 1)
+			decision := &project.Decisions[0]
+			vote := models.NewVote(decision.ID, project.ID, "agent", "A")
+			vote.Strategy = []string{"random", "consensus"}[i%2]
+			if err := tracker.RecordDecision(project, decision, []*models.Vote{vote}, scorer); err != nil {
+				errs <- err
+				return
+			}
+			if err := tracker.RecordProject(project, scorer); err != nil {
+				errs <- err
+			}
+		}(i, tracker)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Failed to record: %v", err)
+	}
+
+	merged, err := metrics.LoadTracker(path)
+	if err != nil {
+		t.Fatalf("Failed to reload tracker: %v", err)
+	}
+	if stats := merged.GetGlobalStats(); stats.TotalProjects != writers || stats.TotalDecisions != writers {
+		t.Errorf("Expected %d projects and decisions, got %d and %d", writers, stats.TotalProjects, stats.TotalDecisions)
+	}
+	for i := 0; i < writers; i++ {
+		project := fmt.Sprintf("project_%d", i)
+		if merged.GetDecisionScore(metrics.DecisionKey(project, project+"-decision")) == nil {
+			t.Errorf("Expected a decision score for %s", project)
+		}
+	}
+	// Strategy outcomes are merged like decision scores
+	performance := merged.AnalyzeStrategyPerformance()
+	for _, strategy := range []string{"random", "consensus"} {
+		if stats := performance[strategy]; stats == nil || stats.TotalUses != writers/2 || stats.SuccessRate != 1 {
+			t.Errorf("Expected %s %d uses at 100%%, got %+v", strategy, writers/2, stats)
+		}
+	}
+}
+
+func TestTrackerRebuild(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.json")
+	scorer := metrics.NewScorer()
+
+	tracker, err := metrics.LoadTracker(path)
+	if err != nil {
+		t.Fatalf("Failed to load tracker: %v", err)
+	}
+	if err := tracker.RecordProject(completedProject("stale", 5), scorer); err != nil {
+		t.Fatalf("Failed to record project: %v", err)
+	}
+
+	active := models.NewProject("active", "Still running", 1, 10)
+	projects := []*models.Project{completedProject("alpha", 1), completedProject("beta", 3), active}
+	if err := tracker.Rebuild(projects, nil, scorer); err != nil {
+		t.Fatalf("Failed to rebuild: %v", err)
+	}
+
+	stats := tracker.GetGlobalStats()
+	if stats.TotalProjects != 2 || stats.TotalDecisions != 4 {
+		t.Errorf("Expected 2 projects and 4 decisions, got %d and %d", stats.TotalProjects, stats.TotalDecisions)
+	}
+	if tracker.GetProjectScore("stale") != nil {
+		t.Error("Expected rebuild to drop projects that are no longer stored")
+	}
+	if tracker.GetProjectScore("active") != nil {
+		t.Error("Expected unfinished projects to be skipped")
+	}
+	if tracker.GetDecisionScore(metrics.DecisionKey("beta", "beta-decision")) == nil {
+		t.Error("Expected decision scores to be rebuilt without a vote store")
+	}
+}
+
+func TestStrategyPerformance(t *testing.T) {
+	dataDir := t.TempDir()
+	scorer := metrics.NewScorer()
+
+	votes, err := storage.NewJSONVoteStore(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to create vote store: %v", err)
+	}
+
+	project := completedProject("alpha", 1)
+	decision := &project.Decisions[0]
+	cast := []struct {
+		strategy string
+		option   string
+	}{
+		{"consensus", "A"},
+		{"consensus", "A"},
+		{"random", "B"},
+		{"random", "A"},
+		{"", "A"}, // direct votes are not attributed to a strategy
+	}
+	for i, c := range cast {
+		vote := models.NewVote(decision.ID, project.ID, fmt.Sprintf("agent_%d", i), c.option)
+		vote.Strategy = c.strategy
+		if err := votes.SaveVote(vote); err != nil {
+			t.Fatalf("Failed to save vote: %v", err)
+		}
+	}
+
+	tracker, err := metrics.LoadTracker(filepath.Join(dataDir, "metrics.json"))
+	if err != nil {
+		t.Fatalf("Failed to load tracker: %v", err)
+	}
+
+	projectVotes, err := votes.GetVotesByProject(project.ID)
+	if err != nil {
+		t.Fatalf("Failed to get votes: %v", err)
+	}
+	// Recording twice must not double count
+	for i := 0; i < 2; i++ {
+		if err := tracker.RecordDecision(project, decision, projectVotes, scorer); err != nil {
+			t.Fatalf("Failed to record decision: %v", err)
+		}
+	}
+
+	check := func(performance map[string]*metrics.StrategyStats) {
+		t.Helper()
+		if len(performance) != 2 {
+			t.Fatalf("Expected 2 strategies, got %d", len(performance))
+		}
+		if stats := performance["consensus"]; stats.TotalUses != 2 || stats.SuccessRate != 1 {
+			t.Errorf("Expected consensus 2 uses at 100%%, got %d at %.2f", stats.TotalUses, stats.SuccessRate)
+		}
+		if stats := performance["random"]; stats.TotalUses != 2 || stats.SuccessRate != 0.5 {
+			t.Errorf("Expected random 2 uses at 50%%, got %d at %.2f", stats.TotalUses, stats.SuccessRate)
+		}
+		if stats := performance["random"]; stats.AverageTime != decision.ConsensusTime() {
+			t.Errorf("Expected average time %v, got %v", decision.ConsensusTime(), stats.AverageTime)
+		}
+	}
+	check(tracker.AnalyzeStrategyPerformance())
+
+	if err := tracker.Rebuild([]*models.Project{project}, votes, scorer); err != nil {
+		t.Fatalf("Failed to rebuild: %v", err)
+	}
+	check(tracker.AnalyzeStrategyPerformance())
+
+	// Global stats are a copy that callers can change without touching the tracker
+	stats := tracker.GetGlobalStats()
+	check(stats.StrategyPerformance)
+	stats.StrategyPerformance["random"].TotalUses = 99
+	delete(stats.StrategyPerformance, "consensus")
+	check(tracker.GetGlobalStats().StrategyPerformance)
+}
+
+func TestStrategyOutcomesCountOnlyCountedVotes(t *testing.T) {
+	scorer := metrics.NewScorer()
+
+	for _, tt := range []struct {
+		policy  models.RevotePolicy
+		uses    int
+		success float64
+	}{
+		{models.RevotePolicyReplacePrevious, 1, 1},
+		{models.RevotePolicyAllowMultiple, 2, 0.5},
+	} {
+		project := completedProject("alpha", 1)
+		project.Settings.RevotePolicy = tt.policy
+		decision := &project.Decisions[0]
+
+		// agent_0 changes their vote from B to the winner A
+		var votes []*models.Vote
+		for _, option := range []string{"B", "A"} {
+			vote := models.NewVote(decision.ID, project.ID, "agent_0", option)
+			vote.Strategy = "random"
+			votes = append(votes, vote)
+		}
+
+		tracker := metrics.NewTracker()
+		if err := tracker.RecordDecision(project, decision, votes, scorer); err != nil {
+			t.Fatalf("Failed to record decision: %v", err)
+		}
+
+		stats := tracker.AnalyzeStrategyPerformance()["random"]
+		if stats == nil || stats.TotalUses != tt.uses || stats.SuccessRate != tt.success {
+			t.Errorf("%s: expected %d uses at %.2f, got %+v", tt.policy, tt.uses, tt.success, stats)
+		}
+	}
+}
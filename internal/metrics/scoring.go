@@ -15,7 +15,9 @@ func NewScorer() *Scorer {
 	return &Scorer{}
 }
 
-// CalculateProjectScore calculates the overall score for a completed project
+// CalculateProjectScore calculates the overall score for a finished project.
+// Cancelled projects are scored on the work they completed but earn no
+// completion bonus.
 func (s *Scorer) CalculateProjectScore(project *models.Project) *GameScore {
 	if !project.IsComplete() {
 		return nil
@@ -31,6 +33,8 @@ func (s *Scorer) CalculateProjectScore(project *models.Project) *GameScore {
 		SpeedScore:               0,
 		ConsensusScore:           0,
 		GameAverageConsensusTime: project.Metrics.AverageConsensusTime, // Populate new field
+		Cancelled:                project.State == models.ProjectStateCancelled,
+		CancelReason:             project.CancelReason,
 	}
 
 	score.TotalScore = project.Metrics.TotalDecisions * 10
@@ -76,12 +80,13 @@ func (s *Scorer) CalculateDecisionScore(decision *models.Decision, k int) *Decis
 		TotalScore:        0,
 	}
 
-	if decision.CompletedAt == nil {
+	// Cancelled, escalated and undecided decisions earn nothing
+	if decision.State != models.DecisionStateCompleted || decision.CompletedAt == nil {
 		return score
 	}
 
 	// Consensus speed (lower time = higher score)
-	consensusTime := decision.CompletedAt.Sub(decision.VotingStarted)
+	consensusTime := decision.ConsensusTime()
 	score.ConsensusSpeed = s.calculateTimeScore(consensusTime)
 
 	// Consensus strength (how decisive the winner was)
@@ -135,8 +140,8 @@ func (s *Scorer) calculateQualityScore(project *models.Project) float64 {
 	// Quality based on consistency of consensus times
 	var times []time.Duration
 	for _, decision := range project.Decisions {
-		if decision.CompletedAt != nil {
-			times = append(times, decision.CompletedAt.Sub(decision.VotingStarted))
+		if decision.Winner != nil && decision.CompletedAt != nil {
+			times = append(times, decision.ConsensusTime())
 		}
 	}
 
@@ -257,6 +262,8 @@ type GameScore struct {
 	SpeedScore               float64       `json:"speed_score"`
 	ConsensusScore           float64       `json:"consensus_score"`
 	GameAverageConsensusTime time.Duration `json:"game_average_consensus_time"` // New field
+	Cancelled                bool          `json:"cancelled,omitempty"`
+	CancelReason             string        `json:"cancel_reason,omitempty"`
 }
 
 // DecisionScore represents the scoring breakdown for a decision
@@ -1,18 +1,37 @@
 package metrics
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/bneil/voter/internal/models"
+	"github.com/bneil/voter/internal/project"
+	"github.com/bneil/voter/internal/storage"
 )
 
 // Tracker tracks and analyzes project metrics over time
 type Tracker struct {
-	mu             sync.RWMutex
-	projectScores  map[string]*GameScore
-	decisionScores map[string]*DecisionScore
-	globalStats    *GlobalStats
+	mu               sync.RWMutex
+	path             string // file the tracker persists to, empty for in-memory only
+	projectScores    map[string]*GameScore
+	projectDecisions map[string]int // project -> decisions counted towards global stats
+	decisionScores   map[string]*DecisionScore
+	strategyOutcomes map[string]map[string]*StrategyOutcome // decision key -> strategy -> outcome
+	globalStats      *GlobalStats
+}
+
+// trackerState is the persisted form of a Tracker
+type trackerState struct {
+	ProjectScores    map[string]*GameScore                  `json:"project_scores"`
+	ProjectDecisions map[string]int                         `json:"project_decisions"`
+	DecisionScores   map[string]*DecisionScore              `json:"decision_scores"`
+	StrategyOutcomes map[string]map[string]*StrategyOutcome `json:"strategy_outcomes"`
+	GlobalStats      *GlobalStats                           `json:"global_stats"`
 }
 
 // GlobalStats represents global statistics across all projects
@@ -34,24 +53,353 @@ type StrategyStats struct {
 	AverageTime  time.Duration `json:"average_time"`
 }
 
+// StrategyOutcome records how one strategy's votes fared in a single decision
+type StrategyOutcome struct {
+	Votes         int           `json:"votes"`
+	Agreed        int           `json:"agreed"` // votes for the eventual winner
+	ConsensusTime time.Duration `json:"consensus_time"`
+	DecisionScore float64       `json:"decision_score"`
+}
+
 // NewTracker creates a new metrics tracker
 func NewTracker() *Tracker {
 	return &Tracker{
-		projectScores:  make(map[string]*GameScore),
-		decisionScores: make(map[string]*DecisionScore),
+		projectScores:    make(map[string]*GameScore),
+		projectDecisions: make(map[string]int),
+		decisionScores:   make(map[string]*DecisionScore),
+		strategyOutcomes: make(map[string]map[string]*StrategyOutcome),
 		globalStats: &GlobalStats{
 			StrategyPerformance: make(map[string]*StrategyStats),
 		},
 	}
 }
 
+// LoadTracker creates a tracker that persists its state to path, loading any
+// state previously saved there
+func LoadTracker(path string) (*Tracker, error) {
+	t := NewTracker()
+	t.path = path
+
+	if err := t.load(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// TrackService loads the tracker persisted in dataDir and registers it on
+// service, so every resolved decision and finished project is scored and
+// saved. Errors recording a score are passed to warn, or dropped if it is nil.
+func TrackService(service *project.Service, votes storage.VoteStore, dataDir string, scorer *Scorer, warn func(error)) (*Tracker, error) {
+	t, err := LoadTracker(filepath.Join(dataDir, "metrics.json"))
+	if err != nil {
+		return nil, err
+	}
+	if warn == nil {
+		warn = func(error) {}
+	}
+
+	service.SetDecisionHandler(func(project *models.Project, decision *models.Decision) {
+		projectVotes, err := votes.GetVotesByProject(project.ID)
+		if err == nil {
+			err = t.RecordDecision(project, decision, projectVotes, scorer)
+		}
+		if err != nil {
+			warn(fmt.Errorf("failed to record decision score: %w", err))
+		}
+	})
+	service.SetCompletionHandler(func(project *models.Project) {
+		if err := t.RecordProject(project, scorer); err != nil {
+			warn(fmt.Errorf("failed to record project score: %w", err))
+		}
+	})
+
+	return t, nil
+}
+
+// load replaces the tracker state with the state saved in its file; callers
+// must hold the lock
+func (t *Tracker) load() error {
+	data, err := os.ReadFile(t.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read metrics: %w", err)
+	}
+
+	var state trackerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to unmarshal metrics: %w", err)
+	}
+
+	if state.ProjectScores != nil {
+		t.projectScores = state.ProjectScores
+	}
+	if state.ProjectDecisions != nil {
+		t.projectDecisions = state.ProjectDecisions
+	}
+	if state.DecisionScores != nil {
+		t.decisionScores = state.DecisionScores
+	}
+	if state.StrategyOutcomes != nil {
+		t.strategyOutcomes = state.StrategyOutcomes
+	}
+	if state.GlobalStats != nil {
+		t.globalStats = state.GlobalStats
+		if t.globalStats.StrategyPerformance == nil {
+			t.globalStats.StrategyPerformance = make(map[string]*StrategyStats)
+		}
+	}
+
+	return nil
+}
+
+// update applies change to the tracker state and saves it. Other processes
+// may record scores in the same file, so the file is locked, reloaded and
+// changed under the lock, and each record is merged with theirs instead of
+// overwriting them.
+func (t *Tracker) update(change func()) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.path == "" {
+		change()
+		return nil
+	}
+
+	unlock, err := storage.LockFile(t.path+".lock", storage.DefaultLockTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to lock metrics: %w", err)
+	}
+	defer unlock()
+
+	if err := t.load(); err != nil {
+		return err
+	}
+	change()
+	return t.save()
+}
+
+// Save writes the tracker state to its file, replacing whatever other
+// processes recorded there. In-memory trackers are not saved.
+func (t *Tracker) Save() error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.save()
+}
+
+// save writes the tracker state; callers must hold the lock
+func (t *Tracker) save() error {
+	if t.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(trackerState{
+		ProjectScores:    t.projectScores,
+		ProjectDecisions: t.projectDecisions,
+		DecisionScores:   t.decisionScores,
+		StrategyOutcomes: t.strategyOutcomes,
+		GlobalStats:      t.globalStats,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metrics: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+
+	if err := storage.WriteFileAtomic(t.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+
+	return nil
+}
+
+// RecordProject scores a finished project and its decisions and saves the
+// result. Recording the same project again replaces its earlier scores.
+func (t *Tracker) RecordProject(project *models.Project, scorer *Scorer) error {
+	score := scorer.CalculateProjectScore(project)
+	if score == nil {
+		return nil
+	}
+
+	return t.update(func() {
+		t.recordProject(project, score, scorer)
+	})
+}
+
+// RecordDecision scores a completed decision and attributes its outcome to
+// the strategies behind the given votes, then saves the result. Decisions
+// that ended without a winner are ignored.
+func (t *Tracker) RecordDecision(project *models.Project, decision *models.Decision, votes []*models.Vote, scorer *Scorer) error {
+	if decision.State != models.DecisionStateCompleted || decision.Winner == nil {
+		return nil
+	}
+
+	return t.update(func() {
+		t.recordDecision(project, decision, votes, scorer)
+		t.updateStrategyPerformance()
+	})
+}
+
+// Rebuild discards all recorded scores and recomputes them from the given
+// projects. Strategy outcomes come from their vote logs, so they are only
+// rebuilt if votes is not nil. Projects that have not finished contribute
+// only their completed decisions.
+func (t *Tracker) Rebuild(projects []*models.Project, votes storage.VoteStore, scorer *Scorer) error {
+	projectVotes := make(map[string][]*models.Vote)
+	if votes != nil {
+		for _, project := range projects {
+			logged, err := votes.GetVotesByProject(project.ID)
+			if err != nil {
+				return fmt.Errorf("failed to read votes for project %s: %w", project.ID, err)
+			}
+			projectVotes[project.ID] = logged
+		}
+	}
+
+	return t.update(func() {
+		t.projectScores = make(map[string]*GameScore)
+		t.projectDecisions = make(map[string]int)
+		t.decisionScores = make(map[string]*DecisionScore)
+		t.strategyOutcomes = make(map[string]map[string]*StrategyOutcome)
+		t.globalStats = &GlobalStats{
+			StrategyPerformance: make(map[string]*StrategyStats),
+		}
+
+		for _, project := range projects {
+			for i := range project.Decisions {
+				decision := &project.Decisions[i]
+				if decision.State == models.DecisionStateCompleted && decision.Winner != nil {
+					t.recordDecision(project, decision, projectVotes[project.ID], scorer)
+				}
+			}
+
+			if score := scorer.CalculateProjectScore(project); score != nil {
+				t.recordProject(project, score, scorer)
+			}
+		}
+		t.updateStrategyPerformance()
+	})
+}
+
+// recordDecision records a decision score and the outcome of each strategy
+// that voted on it; callers must hold the lock
+func (t *Tracker) recordDecision(project *models.Project, decision *models.Decision, votes []*models.Vote, scorer *Scorer) {
+	key := DecisionKey(project.ID, decision.ID)
+	score := scorer.CalculateDecisionScore(decision, project.K)
+	t.decisionScores[key] = score
+
+	outcomes := make(map[string]*StrategyOutcome)
+	for _, vote := range countedVotes(project, decision, votes) {
+		if vote.Strategy == "" {
+			continue
+		}
+
+		outcome, exists := outcomes[vote.Strategy]
+		if !exists {
+			outcome = &StrategyOutcome{
+				ConsensusTime: decision.ConsensusTime(),
+				DecisionScore: score.TotalScore,
+			}
+			outcomes[vote.Strategy] = outcome
+		}
+		outcome.Votes++
+		if vote.Option == *decision.Winner {
+			outcome.Agreed++
+		}
+	}
+
+	if len(outcomes) > 0 {
+		t.strategyOutcomes[key] = outcomes
+	} else {
+		delete(t.strategyOutcomes, key)
+	}
+}
+
+// countedVotes returns the votes from a vote log that count towards a
+// decision's tally, in the order they were cast. Red-flagged votes never
+// count, and under the replace-previous policy only each agent's latest vote does.
+func countedVotes(project *models.Project, decision *models.Decision, votes []*models.Vote) []*models.Vote {
+	var counted []*models.Vote
+	latest := make(map[string]int) // agent -> index of their vote in counted
+	for _, vote := range votes {
+		if vote.Flagged || vote.DecisionID != decision.ID || vote.ProjectID != project.ID {
+			continue
+		}
+		if project.RevotePolicy() == models.RevotePolicyReplacePrevious {
+			if i, voted := latest[vote.AgentID]; voted {
+				counted[i] = nil
+			}
+			latest[vote.AgentID] = len(counted)
+		}
+		counted = append(counted, vote)
+	}
+
+	return slices.DeleteFunc(counted, func(vote *models.Vote) bool { return vote == nil })
+}
+
+// updateStrategyPerformance recomputes per-strategy statistics from the
+// recorded decision outcomes. Time and score averages are weighted by the
+// number of votes each strategy cast in a decision.
+func (t *Tracker) updateStrategyPerformance() {
+	performance := make(map[string]*StrategyStats)
+	totalTime := make(map[string]time.Duration)
+	totalScore := make(map[string]float64)
+	agreed := make(map[string]int)
+
+	for _, outcomes := range t.strategyOutcomes {
+		for strategy, outcome := range outcomes {
+			stats, exists := performance[strategy]
+			if !exists {
+				stats = &StrategyStats{}
+				performance[strategy] = stats
+			}
+			stats.TotalUses += outcome.Votes
+			agreed[strategy] += outcome.Agreed
+			totalTime[strategy] += outcome.ConsensusTime * time.Duration(outcome.Votes)
+			totalScore[strategy] += outcome.DecisionScore * float64(outcome.Votes)
+		}
+	}
+
+	for strategy, stats := range performance {
+		if stats.TotalUses == 0 {
+			continue
+		}
+		stats.SuccessRate = float64(agreed[strategy]) / float64(stats.TotalUses)
+		stats.AverageTime = totalTime[strategy] / time.Duration(stats.TotalUses)
+		stats.AverageScore = totalScore[strategy] / float64(stats.TotalUses)
+	}
+
+	t.globalStats.StrategyPerformance = performance
+}
+
+// recordProject records a project score and its decision scores; callers must hold the lock
+func (t *Tracker) recordProject(project *models.Project, score *GameScore, scorer *Scorer) {
+	for i := range project.Decisions {
+		decision := &project.Decisions[i]
+		t.decisionScores[DecisionKey(project.ID, decision.ID)] = scorer.CalculateDecisionScore(decision, project.K)
+	}
+
+	t.projectScores[project.ID] = score
+	t.updateGlobalStats(project)
+}
+
+// DecisionKey returns the key decision scores are recorded under. Decision
+// IDs are only unique within a project, so the key includes the project ID.
+func DecisionKey(projectID, decisionID string) string {
+	return projectID + "/" + decisionID
+}
+
 // RecordProjectScore records the score for a completed project
 func (t *Tracker) RecordProjectScore(project *models.Project, score *GameScore) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
 	t.projectScores[project.ID] = score
-	t.updateGlobalStats(project, score)
+	t.updateGlobalStats(project)
 }
 
 // RecordDecisionScore records the score for a completed decision
@@ -85,6 +433,11 @@ func (t *Tracker) GetGlobalStats() *GlobalStats {
 
 	// Return a copy to avoid race conditions
 	stats := *t.globalStats
+	stats.StrategyPerformance = make(map[string]*StrategyStats, len(t.globalStats.StrategyPerformance))
+	for strategy, performance := range t.globalStats.StrategyPerformance {
+		copied := *performance
+		stats.StrategyPerformance[strategy] = &copied
+	}
 	return &stats
 }
 
@@ -119,9 +472,12 @@ func (t *Tracker) AnalyzeStrategyPerformance() map[string]*StrategyStats {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
-	// This would require tracking which strategies were used for each decision
-	// For now, return the current strategy performance
-	return t.globalStats.StrategyPerformance
+	performance := make(map[string]*StrategyStats, len(t.globalStats.StrategyPerformance))
+	for strategy, stats := range t.globalStats.StrategyPerformance {
+		copied := *stats
+		performance[strategy] = &copied
+	}
+	return performance
 }
 
 // GetPerformanceTrends analyzes performance trends over time
@@ -147,10 +503,17 @@ func (t *Tracker) GetPerformanceTrends() *PerformanceTrends {
 	return trends
 }
 
-// updateGlobalStats updates the global statistics with new game data
-func (t *Tracker) updateGlobalStats(project *models.Project, score *GameScore) {
-	t.globalStats.TotalProjects++
-	t.globalStats.TotalDecisions += project.Metrics.TotalDecisions
+// updateGlobalStats updates the global statistics with new game data. The
+// totals are recomputed from the recorded scores, so recording a project
+// twice does not count it twice.
+func (t *Tracker) updateGlobalStats(project *models.Project) {
+	t.projectDecisions[project.ID] = project.Metrics.TotalDecisions
+
+	t.globalStats.TotalProjects = len(t.projectScores)
+	t.globalStats.TotalDecisions = 0
+	for _, decisions := range t.projectDecisions {
+		t.globalStats.TotalDecisions += decisions
+	}
 
 	// Update average project score
 	totalScoreSum := 0
@@ -164,9 +527,14 @@ func (t *Tracker) updateGlobalStats(project *models.Project, score *GameScore) {
 	}
 
 	// Update best project
-	if score.TotalScore > t.globalStats.BestProjectScore {
-		t.globalStats.BestProjectScore = score.TotalScore
-		t.globalStats.BestProjectID = project.ID
+	t.globalStats.BestProjectScore = 0
+	t.globalStats.BestProjectID = ""
+	for id, s := range t.projectScores {
+		best := t.globalStats.BestProjectScore
+		if s.TotalScore > best || (s.TotalScore == best && id < t.globalStats.BestProjectID) {
+			t.globalStats.BestProjectScore = s.TotalScore
+			t.globalStats.BestProjectID = id
+		}
 	}
 
 	// Update average consensus time
@@ -0,0 +1,163 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidHistory = errors.New("invalid project history")
+)
+
+// HistoryEventType identifies a change recorded in a project's history
+type HistoryEventType string
+
+const (
+	// HistoryProjectCreated carries the new project
+	HistoryProjectCreated HistoryEventType = "project_created"
+	// HistoryDecisionStarted carries the new decision
+	HistoryDecisionStarted HistoryEventType = "decision_started"
+	// HistoryVoteCast records one vote, counted or red-flagged
+	HistoryVoteCast HistoryEventType = "vote_cast"
+	// HistoryDecisionResolved carries a decision that stopped accepting votes
+	// and the project metrics afterwards
+	HistoryDecisionResolved HistoryEventType = "decision_resolved"
+	// HistoryDecisionExtended carries a decision whose deadline was pushed back
+	HistoryDecisionExtended HistoryEventType = "decision_extended"
+	// HistoryProjectPaused records that a project was paused
+	HistoryProjectPaused HistoryEventType = "project_paused"
+	// HistoryProjectResumed records that a project was resumed, with the
+	// current decision after its clock was moved on
+	HistoryProjectResumed HistoryEventType = "project_resumed"
+	// HistoryProjectEnded records that a project was completed or cancelled
+	HistoryProjectEnded HistoryEventType = "project_ended"
+)
+
+// HistoryEvent is one entry in a project's ordered history. Folding a
+// project's events in order with Apply rebuilds the project as it was saved.
+type HistoryEvent struct {
+	Seq        int64            `json:"seq"`     // position in the project's history, from 1
+	Version    int64            `json:"version"` // project version after the save that recorded the event
+	Type       HistoryEventType `json:"type"`
+	Time       time.Time        `json:"time"`
+	ProjectID  string           `json:"project_id"`
+	DecisionID string           `json:"decision_id,omitempty"`
+	AgentID    string           `json:"agent_id,omitempty"`
+	Option     string           `json:"option,omitempty"` // canonical option of a counted vote
+	Flagged    bool             `json:"flagged,omitempty"`
+	Project    *Project         `json:"project,omitempty"`  // project_created
+	Decision   *Decision        `json:"decision,omitempty"` // decision_started, decision_resolved, decision_extended, project_resumed
+	Metrics    *ProjectMetrics  `json:"metrics,omitempty"`  // decision_resolved
+	State      ProjectState     `json:"state,omitempty"`    // project_ended
+	Reason     string           `json:"reason,omitempty"`   // project_ended: cancellation reason
+}
+
+// Apply folds a history event into the project. Applying a project_created
+// event replaces the project entirely, so a history can be folded into a
+// zero Project.
+func (p *Project) Apply(event *HistoryEvent) error {
+	switch event.Type {
+	case HistoryProjectCreated:
+		if event.Project == nil {
+			return fmt.Errorf("%w: event %d has no project", ErrInvalidHistory, event.Seq)
+		}
+		*p = *event.Project.clone()
+
+	case HistoryDecisionStarted:
+		if event.Decision == nil {
+			return fmt.Errorf("%w: event %d has no decision", ErrInvalidHistory, event.Seq)
+		}
+		p.Decisions = append(p.Decisions, *event.Decision.clone())
+		p.CurrentTurn = event.Decision.TurnNumber
+
+	case HistoryVoteCast:
+		decision := p.FindDecision(event.DecisionID)
+		if decision == nil {
+			return fmt.Errorf("%w: event %d votes on unknown decision %s", ErrInvalidHistory, event.Seq, event.DecisionID)
+		}
+		if event.Flagged {
+			decision.FlaggedVotes++
+		} else if err := decision.AddAgentVote(event.AgentID, event.Option, p.RevotePolicy()); err != nil {
+			return fmt.Errorf("%w: event %d: %w", ErrInvalidHistory, event.Seq, err)
+		}
+
+	case HistoryDecisionResolved, HistoryDecisionExtended, HistoryProjectResumed:
+		if event.Decision != nil {
+			decision := p.FindDecision(event.Decision.ID)
+			if decision == nil {
+				return fmt.Errorf("%w: event %d updates unknown decision %s", ErrInvalidHistory, event.Seq, event.Decision.ID)
+			}
+			*decision = *event.Decision.clone()
+		}
+		if event.Metrics != nil {
+			p.Metrics = *event.Metrics
+		}
+		if event.Type == HistoryProjectResumed {
+			p.State = ProjectStateActive
+			p.PausedAt = nil
+		}
+
+	case HistoryProjectPaused:
+		pausedAt := event.Time
+		p.State = ProjectStatePaused
+		p.PausedAt = &pausedAt
+
+	case HistoryProjectEnded:
+		completedAt := event.Time
+		p.State = event.State
+		p.CompletedAt = &completedAt
+		p.PausedAt = nil
+		if event.State == ProjectStateCancelled {
+			p.CancelReason = event.Reason
+		}
+
+	default:
+		return fmt.Errorf("%w: event %d has unknown type %q", ErrInvalidHistory, event.Seq, event.Type)
+	}
+
+	p.UpdatedAt = event.Time
+	p.Version = event.Version
+	p.HistorySeq = event.Seq
+	return nil
+}
+
+// clone returns a deep copy of the project, so folding events into it never
+// changes the events themselves
+func (p *Project) clone() *Project {
+	c := *p
+	c.Decisions = make([]Decision, len(p.Decisions))
+	for i := range p.Decisions {
+		c.Decisions[i] = *p.Decisions[i].clone()
+	}
+	return &c
+}
+
+// clone returns a copy of the decision that shares no maps or slices with it
+func (d *Decision) clone() *Decision {
+	c := *d
+	c.Options = append([]string(nil), d.Options...)
+	c.Votes = make(map[string]int, len(d.Votes))
+	for option, count := range d.Votes {
+		c.Votes[option] = count
+	}
+	if d.AgentVotes != nil {
+		c.AgentVotes = make(map[string]string, len(d.AgentVotes))
+		for agent, option := range d.AgentVotes {
+			c.AgentVotes[agent] = option
+		}
+	}
+	if d.AgentSequences != nil {
+		c.AgentSequences = make(map[string]int, len(d.AgentSequences))
+		for agent, sequence := range d.AgentSequences {
+			c.AgentSequences[agent] = sequence
+		}
+	}
+	if d.ReachedAt != nil {
+		c.ReachedAt = make(map[string][]int, len(d.ReachedAt))
+		for option, reached := range d.ReachedAt {
+			c.ReachedAt[option] = append([]int(nil), reached...)
+		}
+	}
+	return &c
+}
@@ -1,6 +1,7 @@
 package models_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/bneil/voter/internal/models"
@@ -196,3 +197,140 @@ func TestGetCurrentDecision(t *testing.T) {
 		t.Errorf("Expected active decision, got %v", decision)
 	}
 }
+
+func TestDecisionAddAgentVote(t *testing.T) {
+	options := []string{"A", "B"}
+
+	// Reject duplicate votes
+	decision := models.NewDecision("test", "game", "test", 1, options)
+	if err := decision.AddAgentVote("agent1", "A", models.RevotePolicyRejectDuplicate); err != nil {
+		t.Fatalf("Expected first vote to succeed, got %v", err)
+	}
+	if err := decision.AddAgentVote("agent1", "B", models.RevotePolicyRejectDuplicate); !errors.Is(err, models.ErrDuplicateVote) {
+		t.Errorf("Expected ErrDuplicateVote, got %v", err)
+	}
+	if decision.Votes["A"] != 1 || decision.Votes["B"] != 0 {
+		t.Errorf("Expected A=1 B=0, got %v", decision.Votes)
+	}
+
+	// Replace the previous vote
+	decision = models.NewDecision("test", "game", "test", 1, options)
+	decision.AddAgentVote("agent1", "A", models.RevotePolicyReplacePrevious)
+	if err := decision.AddAgentVote("agent1", "B", models.RevotePolicyReplacePrevious); err != nil {
+		t.Fatalf("Expected replacement vote to succeed, got %v", err)
+	}
+	if decision.Votes["A"] != 0 || decision.Votes["B"] != 1 {
+		t.Errorf("Expected A=0 B=1, got %v", decision.Votes)
+	}
+
+	// Allow multiple votes
+	decision = models.NewDecision("test", "game", "test", 1, options)
+	decision.AddAgentVote("agent1", "A", models.RevotePolicyAllowMultiple)
+	decision.AddAgentVote("agent1", "A", models.RevotePolicyAllowMultiple)
+	if decision.Votes["A"] != 2 {
+		t.Errorf("Expected A=2, got %d", decision.Votes["A"])
+	}
+
+	// Invalid options are rejected without registering the agent
+	if err := decision.AddAgentVote("agent2", "C", models.RevotePolicyRejectDuplicate); !errors.Is(err, models.ErrVoteRejected) {
+		t.Errorf("Expected ErrVoteRejected, got %v", err)
+	}
+	if decision.HasVoted("agent2") {
+		t.Error("Expected rejected vote to not register the agent")
+	}
+}
+
+func TestDecisionTieBreak(t *testing.T) {
+	options := []string{"A", "B", "C"}
+
+	// Votes: B, C, C, B -> B and C tied at 2, C reached 2 first, B voted first
+	newTied := func(rule models.TieBreakRule) *models.Decision {
+		decision := models.NewDecision("test", "game", "test", 1, options)
+		decision.TieBreak = rule
+		for _, option := range []string{"B", "C", "C", "B"} {
+			decision.AddVote(option)
+		}
+		return decision
+	}
+
+	tests := []struct {
+		rule   models.TieBreakRule
+		leader string
+	}{
+		{models.TieBreakFirstToReach, "C"},
+		{models.TieBreakDeclarationOrder, "B"},
+		{models.TieBreakEarliestVote, "B"},
+	}
+
+	for _, tt := range tests {
+		decision := newTied(tt.rule)
+
+		// Repeat to make sure map iteration order never leaks into the result
+		for i := 0; i < 20; i++ {
+			if leader, votes := decision.Leader(); leader != tt.leader || votes != 2 {
+				t.Fatalf("%s: expected leader %s with 2 votes, got %s with %d", tt.rule, tt.leader, leader, votes)
+			}
+			if winner := decision.CheckWinner(0); winner == nil || *winner != tt.leader {
+				t.Fatalf("%s: expected K=0 winner %s, got %v", tt.rule, tt.leader, winner)
+			}
+		}
+
+		if winner := decision.CheckWinner(1); winner != nil {
+			t.Errorf("%s: expected no winner with K=1 on a tie, got %s", tt.rule, *winner)
+		}
+	}
+}
+
+func TestDecisionTieBreakAfterReplacedVote(t *testing.T) {
+	decision := models.NewDecision("test", "game", "test", 1, []string{"A", "B", "C"})
+	decision.TieBreak = models.TieBreakEarliestVote
+
+	// a1's first vote for A is withdrawn, so A's only counted vote is the third
+	for _, vote := range [][2]string{{"a1", "A"}, {"a2", "B"}, {"a3", "A"}, {"a1", "C"}} {
+		if err := decision.AddAgentVote(vote[0], vote[1], models.RevotePolicyReplacePrevious); err != nil {
+			t.Fatalf("Failed to add vote %v: %v", vote, err)
+		}
+	}
+
+	if reached := decision.ReachedAt["A"]; len(reached) != 1 || reached[0] != 3 {
+		t.Errorf("Expected A's counted vote at sequence 3, got %v", reached)
+	}
+	if leader, votes := decision.Leader(); leader != "B" || votes != 1 {
+		t.Errorf("Expected leader B with 1 vote, got %s with %d", leader, votes)
+	}
+}
+
+func TestDecisionCheckWinnerNoVotesWithZeroK(t *testing.T) {
+	decision := models.NewDecision("test", "game", "test", 1, []string{"A", "B"})
+
+	if winner := decision.CheckWinner(0); winner != nil {
+		t.Errorf("Expected no winner before any votes with K=0, got %s", *winner)
+	}
+}
+
+func TestOpenDecisionDiscoversCandidates(t *testing.T) {
+	decision := models.NewOpenDecision("test", "game", "test", 1, nil)
+
+	if !decision.AddVote("A->B") {
+		t.Fatal("Expected open decision to accept a new answer")
+	}
+
+	// A sole candidate still has to get K votes ahead of the (empty) field
+	if winner := decision.CheckWinner(2); winner != nil {
+		t.Errorf("Expected no winner after a single vote with K=2, got %s", *winner)
+	}
+	decision.AddVote("A->C")
+	decision.AddVote("A->B")
+
+	if len(decision.Options) != 2 || decision.Options[0] != "A->B" || decision.Options[1] != "A->C" {
+		t.Errorf("Expected candidates [A->B A->C] in order of discovery, got %v", decision.Options)
+	}
+
+	if decision.AddVote("") {
+		t.Error("Expected empty answer to be rejected")
+	}
+
+	if winner := decision.CheckWinner(1); winner == nil || *winner != "A->B" {
+		t.Errorf("Expected A->B to be winner, got %v", winner)
+	}
+}
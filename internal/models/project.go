@@ -1,9 +1,23 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"sync/atomic"
 	"time"
 )
 
+var (
+	ErrDuplicateVote       = errors.New("agent has already voted on this decision")
+	ErrVoteRejected        = errors.New("vote rejected")
+	ErrInvalidRevotePolicy = errors.New("invalid re-vote policy")
+	ErrInvalidTieBreak     = errors.New("invalid tie-break rule")
+	ErrInvalidFallback     = errors.New("invalid fallback action")
+)
+
 // ProjectState represents the current state of a project session
 type ProjectState string
 
@@ -16,33 +30,117 @@ const (
 
 // Project represents a complete project session
 type Project struct {
-	ID          string         `json:"id"`
-	Name        string         `json:"name"`
-	State       ProjectState   `json:"state"`
-	K           int            `json:"k"`         // K-ahead threshold
-	MaxTurns    int            `json:"max_turns"` // Maximum number of turns
-	CurrentTurn int            `json:"current_turn"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	CompletedAt *time.Time     `json:"completed_at,omitempty"`
-	Score       int            `json:"score"` // Overall project score
-	Metrics     ProjectMetrics `json:"metrics"`
-	Decisions   []Decision     `json:"decisions"`
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	State        ProjectState    `json:"state"`
+	Version      int64           `json:"version"`               // Incremented by every save
+	HistorySeq   int64           `json:"history_seq,omitempty"` // Last history event folded into this state
+	K            int             `json:"k"`                     // K-ahead threshold
+	MaxTurns     int             `json:"max_turns"`             // Maximum number of turns
+	CurrentTurn  int             `json:"current_turn"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	CompletedAt  *time.Time      `json:"completed_at,omitempty"`
+	PausedAt     *time.Time      `json:"paused_at,omitempty"`
+	CancelReason string          `json:"cancel_reason,omitempty"`
+	Score        int             `json:"score"` // Overall project score
+	Metrics      ProjectMetrics  `json:"metrics"`
+	Settings     ProjectSettings `json:"settings"`
+	Decisions    []Decision      `json:"decisions"`
 }
 
+// ProjectSettings holds per-project voting policies
+type ProjectSettings struct {
+	RevotePolicy RevotePolicy     `json:"revote_policy,omitempty"`
+	TieBreak     TieBreakRule     `json:"tie_break,omitempty"`
+	Normalizers  []NormalizerSpec `json:"normalizers,omitempty"` // applied to answers before tallying
+	RedFlags     RedFlagRules     `json:"red_flags"`             // votes matching these are discarded
+
+	MaxVotesPerDecision int            `json:"max_votes_per_decision,omitempty"` // 0 means unlimited
+	BudgetFallback      FallbackAction `json:"budget_fallback,omitempty"`        // applied when the budget is exhausted
+
+	DecisionTimeout time.Duration  `json:"decision_timeout,omitempty"` // default deadline for new decisions, 0 means none
+	TimeoutOutcome  FallbackAction `json:"timeout_outcome,omitempty"`  // applied when a deadline passes
+}
+
+// FallbackAction determines how a decision is resolved when K-ahead consensus is not reached
+type FallbackAction string
+
+const (
+	// FallbackPlurality declares the current leader the winner
+	FallbackPlurality FallbackAction = "plurality"
+	// FallbackCancel cancels the decision with no consensus
+	FallbackCancel FallbackAction = "cancel"
+	// FallbackEscalate hands the decision off to an escalation handler
+	FallbackEscalate FallbackAction = "escalate"
+	// FallbackExtend keeps voting open for another timeout window; only valid for deadlines
+	FallbackExtend FallbackAction = "extend"
+)
+
+// RedFlagRules describes raw answers that signal an unreliable sample
+type RedFlagRules struct {
+	MaxLength        int      `json:"max_length,omitempty"`         // maximum answer length in characters
+	RequiredPattern  string   `json:"required_pattern,omitempty"`   // regex the whole answer must match
+	RequireJSON      bool     `json:"require_json,omitempty"`       // answer must be valid JSON
+	RequiredJSONKeys []string `json:"required_json_keys,omitempty"` // keys a JSON object answer must contain
+	BannedTokens     []string `json:"banned_tokens,omitempty"`      // substrings that must not appear
+}
+
+// NormalizerSpec configures one step of the answer canonicalization chain
+type NormalizerSpec struct {
+	Type        string `json:"type"`
+	Pattern     string `json:"pattern,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// RevotePolicy controls what happens when an agent votes more than once on a decision
+type RevotePolicy string
+
+const (
+	RevotePolicyRejectDuplicate RevotePolicy = "reject-duplicate"
+	RevotePolicyReplacePrevious RevotePolicy = "replace-previous"
+	RevotePolicyAllowMultiple   RevotePolicy = "allow-multiple"
+)
+
+// TieBreakRule determines which option leads when several share the highest vote count
+type TieBreakRule string
+
+const (
+	// TieBreakFirstToReach favours the option that reached the tied count first
+	TieBreakFirstToReach TieBreakRule = "first-to-reach"
+	// TieBreakDeclarationOrder favours the option listed first in the decision
+	TieBreakDeclarationOrder TieBreakRule = "declaration-order"
+	// TieBreakEarliestVote favours the option whose earliest counted vote came first
+	TieBreakEarliestVote TieBreakRule = "earliest-vote"
+)
+
 // Decision represents a single voting decision within a project
 type Decision struct {
-	ID            string         `json:"id"`
-	ProjectID     string         `json:"project_id"`
-	TurnNumber    int            `json:"turn_number"`
-	Description   string         `json:"description"`
-	Options       []string       `json:"options"`
-	State         DecisionState  `json:"state"`
-	Winner        *string        `json:"winner,omitempty"`
-	Votes         map[string]int `json:"votes"` // option -> vote count
-	CreatedAt     time.Time      `json:"created_at"`
-	CompletedAt   *time.Time     `json:"completed_at,omitempty"`
-	VotingStarted time.Time      `json:"voting_started"`
+	ID               string            `json:"id"`
+	ProjectID        string            `json:"project_id"`
+	TurnNumber       int               `json:"turn_number"`
+	Description      string            `json:"description"`
+	Options          []string          `json:"options"`
+	Open             bool              `json:"open,omitempty"` // candidates are discovered from votes
+	State            DecisionState     `json:"state"`
+	Winner           *string           `json:"winner,omitempty"`
+	Votes            map[string]int    `json:"votes"`                     // option -> vote count
+	AgentVotes       map[string]string `json:"agent_votes,omitempty"`     // agent -> latest option
+	AgentSequences   map[string]int    `json:"agent_sequences,omitempty"` // agent -> sequence of latest vote
+	TieBreak         TieBreakRule      `json:"tie_break,omitempty"`
+	VoteSequence     int               `json:"vote_sequence"`           // number of votes added so far
+	ReachedAt        map[string][]int  `json:"reached_at,omitempty"`    // option -> sequences of its counted votes, in order
+	FlaggedVotes     int               `json:"flagged_votes,omitempty"` // red-flagged votes excluded from Votes
+	Resolution       Resolution        `json:"resolution,omitempty"`
+	ResolutionReason string            `json:"resolution_reason,omitempty"`
+	Deadline         *time.Time        `json:"deadline,omitempty"`
+	Timeout          time.Duration     `json:"timeout,omitempty"` // length of the voting window, used for extensions
+	Extensions       int               `json:"extensions,omitempty"`
+	ExpiredAt        *time.Time        `json:"expired_at,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+	CompletedAt      *time.Time        `json:"completed_at,omitempty"`
+	VotingStarted    time.Time         `json:"voting_started"`
+	PausedDuration   time.Duration     `json:"paused_duration,omitempty"` // time spent with the project paused
 }
 
 // DecisionState represents the state of a decision
@@ -52,6 +150,18 @@ const (
 	DecisionStateVoting    DecisionState = "voting"
 	DecisionStateCompleted DecisionState = "completed"
 	DecisionStateCancelled DecisionState = "cancelled"
+	DecisionStateEscalated DecisionState = "escalated"
+)
+
+// Resolution records how a decision was resolved
+type Resolution string
+
+const (
+	ResolutionKAhead      Resolution = "k-ahead"
+	ResolutionPlurality   Resolution = "plurality"
+	ResolutionNoConsensus Resolution = "no-consensus"
+	ResolutionEscalated   Resolution = "escalated"
+	ResolutionCancelled   Resolution = "cancelled" // cancelled by an operator
 )
 
 // ProjectMetrics tracks performance metrics for the project
@@ -67,7 +177,11 @@ type Vote struct {
 	DecisionID string    `json:"decision_id"`
 	ProjectID  string    `json:"project_id"`
 	AgentID    string    `json:"agent_id"`
-	Option     string    `json:"option"`
+	Option     string    `json:"option"`               // canonical option the vote counted for
+	RawOption  string    `json:"raw_option,omitempty"` // answer as submitted by the agent
+	Flagged    bool      `json:"flagged,omitempty"`    // discarded by a red-flag rule
+	FlagReason string    `json:"flag_reason,omitempty"`
+	Strategy   string    `json:"strategy,omitempty"` // strategy that chose the option, for strategic and simulated votes
 	Timestamp  time.Time `json:"timestamp"`
 }
 
@@ -102,15 +216,112 @@ func NewDecision(id, projectID, description string, turnNumber int, options []st
 	}
 
 	return &Decision{
-		ID:            id,
-		ProjectID:     projectID,
-		TurnNumber:    turnNumber,
-		Description:   description,
-		Options:       options,
-		State:         DecisionStateVoting,
-		Votes:         votes,
-		CreatedAt:     now,
-		VotingStarted: now,
+		ID:             id,
+		ProjectID:      projectID,
+		TurnNumber:     turnNumber,
+		Description:    description,
+		Options:        options,
+		State:          DecisionStateVoting,
+		Votes:          votes,
+		AgentVotes:     make(map[string]string),
+		AgentSequences: make(map[string]int),
+		ReachedAt:      make(map[string][]int),
+		CreatedAt:      now,
+		VotingStarted:  now,
+	}
+}
+
+// NewOpenDecision creates a new open-candidate decision, where any submitted
+// answer becomes a candidate option the first time it is voted for
+func NewOpenDecision(id, projectID, description string, turnNumber int, candidates []string) *Decision {
+	decision := NewDecision(id, projectID, description, turnNumber, append([]string{}, candidates...))
+	decision.Open = true
+	return decision
+}
+
+// ParseRevotePolicy parses a re-vote policy name
+func ParseRevotePolicy(name string) (RevotePolicy, error) {
+	switch policy := RevotePolicy(name); policy {
+	case RevotePolicyRejectDuplicate, RevotePolicyReplacePrevious, RevotePolicyAllowMultiple:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidRevotePolicy, name)
+	}
+}
+
+// ParseTieBreakRule parses a tie-break rule name
+func ParseTieBreakRule(name string) (TieBreakRule, error) {
+	switch rule := TieBreakRule(name); rule {
+	case TieBreakFirstToReach, TieBreakDeclarationOrder, TieBreakEarliestVote:
+		return rule, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidTieBreak, name)
+	}
+}
+
+// ParseFallbackAction parses a fallback action name
+func ParseFallbackAction(name string) (FallbackAction, error) {
+	switch action := FallbackAction(name); action {
+	case FallbackPlurality, FallbackCancel, FallbackEscalate:
+		return action, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidFallback, name)
+	}
+}
+
+// ParseTimeoutOutcome parses the action taken when a decision deadline passes,
+// which may be any fallback action or extend
+func ParseTimeoutOutcome(name string) (FallbackAction, error) {
+	if FallbackAction(name) == FallbackExtend {
+		return FallbackExtend, nil
+	}
+	return ParseFallbackAction(name)
+}
+
+// Validate checks that the settings hold supported values
+func (s ProjectSettings) Validate() error {
+	if s.RevotePolicy != "" {
+		if _, err := ParseRevotePolicy(string(s.RevotePolicy)); err != nil {
+			return err
+		}
+	}
+	if s.TieBreak != "" {
+		if _, err := ParseTieBreakRule(string(s.TieBreak)); err != nil {
+			return err
+		}
+	}
+	if s.BudgetFallback != "" {
+		if _, err := ParseFallbackAction(string(s.BudgetFallback)); err != nil {
+			return err
+		}
+	}
+	if s.MaxVotesPerDecision < 0 {
+		return errors.New("max votes per decision cannot be negative")
+	}
+	if s.TimeoutOutcome != "" {
+		if _, err := ParseTimeoutOutcome(string(s.TimeoutOutcome)); err != nil {
+			return err
+		}
+	}
+	if s.DecisionTimeout < 0 {
+		return errors.New("decision timeout cannot be negative")
+	}
+	return nil
+}
+
+// voteSequence disambiguates vote IDs generated within the same clock tick
+var voteSequence atomic.Uint64
+
+// NewVote creates a new vote record with a generated ID and the current timestamp
+func NewVote(decisionID, projectID, agentID, option string) *Vote {
+	now := time.Now()
+	return &Vote{
+		ID:         fmt.Sprintf("vote_%d_%d", now.UnixNano(), voteSequence.Add(1)),
+		DecisionID: decisionID,
+		ProjectID:  projectID,
+		AgentID:    agentID,
+		Option:     option,
+		Timestamp:  now,
 	}
 }
 
@@ -124,6 +335,61 @@ func (p *Project) CanAcceptVotes() bool {
 	return p.State == ProjectStateActive
 }
 
+// RevotePolicy returns the project's re-vote policy, defaulting to reject-duplicate
+func (p *Project) RevotePolicy() RevotePolicy {
+	if p.Settings.RevotePolicy == "" {
+		return RevotePolicyRejectDuplicate
+	}
+	return p.Settings.RevotePolicy
+}
+
+// TieBreakRule returns the project's tie-break rule, defaulting to first-to-reach
+func (p *Project) TieBreakRule() TieBreakRule {
+	if p.Settings.TieBreak == "" {
+		return TieBreakFirstToReach
+	}
+	return p.Settings.TieBreak
+}
+
+// BudgetFallback returns the action taken when a decision exhausts its vote
+// budget, defaulting to plurality
+func (p *Project) BudgetFallback() FallbackAction {
+	if p.Settings.BudgetFallback == "" {
+		return FallbackPlurality
+	}
+	return p.Settings.BudgetFallback
+}
+
+// TimeoutOutcome returns the action taken when a decision deadline passes,
+// defaulting to plurality
+func (p *Project) TimeoutOutcome() FallbackAction {
+	if p.Settings.TimeoutOutcome == "" {
+		return FallbackPlurality
+	}
+	return p.Settings.TimeoutOutcome
+}
+
+// FindDecision returns the decision with the given ID, if any
+func (p *Project) FindDecision(id string) *Decision {
+	for i := range p.Decisions {
+		if p.Decisions[i].ID == id {
+			return &p.Decisions[i]
+		}
+	}
+	return nil
+}
+
+// TurnsUsed returns the turn of the latest decision that was not cancelled.
+// Cancelled decisions give their turn back, so the next decision retries it.
+func (p *Project) TurnsUsed() int {
+	for i := len(p.Decisions) - 1; i >= 0; i-- {
+		if p.Decisions[i].State != DecisionStateCancelled {
+			return p.Decisions[i].TurnNumber
+		}
+	}
+	return 0
+}
+
 // GetCurrentDecision returns the current active decision, if any
 func (p *Project) GetCurrentDecision() *Decision {
 	for i := len(p.Decisions) - 1; i >= 0; i-- {
@@ -134,30 +400,89 @@ func (p *Project) GetCurrentDecision() *Decision {
 	return nil
 }
 
-// CheckWinner determines if any option has reached the K-ahead threshold
+// CheckWinner determines if any option has reached the K-ahead threshold.
+// The leader is chosen by RankedOptions, so ties are resolved deterministically.
 func (d *Decision) CheckWinner(k int) *string {
-	if len(d.Votes) == 0 {
-		return nil
+	leader, maxVotes := d.Leader()
+	if maxVotes == 0 || maxVotes < k {
+		return nil // Candidates not yet voted for count as zero
 	}
 
-	// Find the option with the most votes
-	var maxOption string
-	maxVotes := 0
+	// Check if it has K more votes than any other option
 	for option, votes := range d.Votes {
-		if votes > maxVotes {
-			maxVotes = votes
-			maxOption = option
+		if option != leader && maxVotes-votes < k {
+			return nil // Not enough of a lead
 		}
 	}
 
-	// Check if it has K more votes than any other option
-	for option, votes := range d.Votes {
-		if option != maxOption && maxVotes-votes < k {
-			return nil // Not enough of a lead
+	return &leader
+}
+
+// Leader returns the leading option and its vote count, or an empty option if
+// no votes have been cast
+func (d *Decision) Leader() (string, int) {
+	ranked := d.RankedOptions()
+	if len(ranked) == 0 || d.Votes[ranked[0]] == 0 {
+		return "", 0
+	}
+	return ranked[0], d.Votes[ranked[0]]
+}
+
+// RankedOptions returns the options ordered from most to fewest votes. Ties are
+// broken by the decision's tie-break rule and then by declaration order, so the
+// same sequence of votes always produces the same ranking.
+func (d *Decision) RankedOptions() []string {
+	declared := make(map[string]int, len(d.Options))
+	ranked := make([]string, 0, len(d.Votes))
+	for i, option := range d.Options {
+		if _, exists := declared[option]; !exists {
+			declared[option] = i
+			ranked = append(ranked, option)
+		}
+	}
+	// Options missing from the declaration sort after it, by name
+	var undeclared []string
+	for option := range d.Votes {
+		if _, exists := declared[option]; !exists {
+			undeclared = append(undeclared, option)
+		}
+	}
+	sort.Strings(undeclared)
+	for _, option := range undeclared {
+		declared[option] = len(declared)
+		ranked = append(ranked, option)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if d.Votes[a] != d.Votes[b] {
+			return d.Votes[a] > d.Votes[b]
+		}
+		if seqA, seqB := d.tieBreakKey(a), d.tieBreakKey(b); seqA != seqB {
+			return seqA < seqB
 		}
+		return declared[a] < declared[b]
+	})
+
+	return ranked
+}
+
+// tieBreakKey returns the vote sequence used to order an option among others
+// with the same count. Lower keys rank first; options without history rank last.
+func (d *Decision) tieBreakKey(option string) int {
+	history := d.ReachedAt[option]
+	if len(history) == 0 || len(history) != d.Votes[option] {
+		return math.MaxInt
 	}
 
-	return &maxOption
+	switch d.TieBreak {
+	case TieBreakDeclarationOrder:
+		return 0
+	case TieBreakEarliestVote:
+		return history[0]
+	default:
+		return history[len(history)-1]
+	}
 }
 
 // AddVote adds a vote to the decision
@@ -167,9 +492,104 @@ func (d *Decision) AddVote(option string) bool {
 	}
 
 	if _, exists := d.Votes[option]; !exists {
-		return false // Invalid option
+		if !d.Open || option == "" {
+			return false // Invalid option
+		}
+		// Open decisions admit new candidates on first sight
+		d.Options = append(d.Options, option)
+		d.Votes[option] = 0
 	}
 
 	d.Votes[option]++
+	d.VoteSequence++
+	if d.ReachedAt == nil {
+		d.ReachedAt = make(map[string][]int)
+	}
+	d.ReachedAt[option] = append(d.ReachedAt[option], d.VoteSequence)
 	return true
 }
+
+// removeVote withdraws the vote for an option cast at sequence. Decisions
+// saved before vote sequences were kept per agent pass 0, which withdraws the
+// option's latest vote.
+func (d *Decision) removeVote(option string, sequence int) {
+	if d.Votes[option] == 0 {
+		return
+	}
+	d.Votes[option]--
+
+	history := d.ReachedAt[option]
+	if len(history) == 0 {
+		return
+	}
+	i := slices.Index(history, sequence)
+	if i < 0 {
+		i = len(history) - 1
+	}
+	d.ReachedAt[option] = slices.Delete(history, i, i+1)
+}
+
+// SetDeadline gives the decision an absolute deadline
+func (d *Decision) SetDeadline(deadline time.Time) {
+	d.Deadline = &deadline
+	d.Timeout = deadline.Sub(d.VotingStarted)
+}
+
+// ConsensusTime returns how long voting ran before the decision was resolved,
+// excluding time the project spent paused
+func (d *Decision) ConsensusTime() time.Duration {
+	if d.CompletedAt == nil {
+		return 0
+	}
+	return d.CompletedAt.Sub(d.VotingStarted) - d.PausedDuration
+}
+
+// VotingDuration returns how long the decision has been open for voting,
+// excluding time the project spent paused
+func (d *Decision) VotingDuration(now time.Time) time.Duration {
+	if d.CompletedAt != nil {
+		return d.ConsensusTime()
+	}
+	return now.Sub(d.VotingStarted) - d.PausedDuration
+}
+
+// IsExpired reports whether the decision is still voting past its deadline
+func (d *Decision) IsExpired(now time.Time) bool {
+	return d.State == DecisionStateVoting && d.Deadline != nil && !now.Before(*d.Deadline)
+}
+
+// HasVoted reports whether the agent has already voted on the decision
+func (d *Decision) HasVoted(agentID string) bool {
+	_, voted := d.AgentVotes[agentID]
+	return voted
+}
+
+// AddAgentVote adds a vote on behalf of an agent, enforcing the re-vote policy.
+// It returns ErrDuplicateVote if the policy forbids the agent voting again and
+// ErrVoteRejected if the decision is closed or the option is invalid.
+func (d *Decision) AddAgentVote(agentID, option string, policy RevotePolicy) error {
+	previous, voted := d.AgentVotes[agentID]
+	if voted && policy != RevotePolicyReplacePrevious && policy != RevotePolicyAllowMultiple {
+		return ErrDuplicateVote
+	}
+	previousSequence := d.AgentSequences[agentID]
+
+	if !d.AddVote(option) {
+		return ErrVoteRejected
+	}
+
+	// Withdraw the agent's earlier vote so only the latest one counts
+	if voted && policy == RevotePolicyReplacePrevious {
+		d.removeVote(previous, previousSequence)
+	}
+
+	if d.AgentVotes == nil {
+		d.AgentVotes = make(map[string]string)
+	}
+	if d.AgentSequences == nil {
+		d.AgentSequences = make(map[string]int)
+	}
+	d.AgentVotes[agentID] = option
+	d.AgentSequences[agentID] = d.VoteSequence
+	return nil
+}
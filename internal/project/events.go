@@ -0,0 +1,129 @@
+package project
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bneil/voter/internal/models"
+)
+
+// EventType identifies what happened in a project
+type EventType string
+
+const (
+	EventVoteCast          EventType = "vote_cast"
+	EventDecisionStarted   EventType = "decision_started"
+	EventDecisionCompleted EventType = "decision_completed" // the decision stopped accepting votes, with or without a winner
+	EventProjectCompleted  EventType = "project_completed"  // the project was completed or cancelled
+)
+
+// eventBufferSize is how many events a slow subscriber can fall behind by
+// before further events are dropped for it
+const eventBufferSize = 64
+
+// Event describes a change to a project
+type Event struct {
+	Type       EventType         `json:"type"`
+	ProjectID  string            `json:"project_id"`
+	DecisionID string            `json:"decision_id,omitempty"`
+	AgentID    string            `json:"agent_id,omitempty"`
+	Option     string            `json:"option,omitempty"`
+	Votes      map[string]int    `json:"votes,omitempty"` // tally after the event
+	State      string            `json:"state,omitempty"` // decision or project state after the event
+	Winner     *string           `json:"winner,omitempty"`
+	Resolution models.Resolution `json:"resolution,omitempty"`
+	Reason     string            `json:"reason,omitempty"`
+	Timestamp  time.Time         `json:"timestamp"`
+}
+
+// EventBus fans project events out to subscribers. Publishing never blocks:
+// subscribers that fall too far behind miss events.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan Event]struct{} // project -> subscriber channels
+}
+
+// NewEventBus creates an empty event bus
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subscribers: make(map[string]map[chan Event]struct{}),
+	}
+}
+
+// Subscribe returns a channel of events for a project and a function that
+// cancels the subscription and closes the channel
+func (b *EventBus) Subscribe(projectID string) (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, eventBufferSize)
+	if b.subscribers[projectID] == nil {
+		b.subscribers[projectID] = make(map[chan Event]struct{})
+	}
+	b.subscribers[projectID][ch] = struct{}{}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			delete(b.subscribers[projectID], ch)
+			if len(b.subscribers[projectID]) == 0 {
+				delete(b.subscribers, projectID)
+			}
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers an event to every subscriber of its project
+func (b *EventBus) Publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers[event.ProjectID] {
+		select {
+		case ch <- event:
+		default:
+			// Subscriber is full; drop the event rather than stall voting
+		}
+	}
+}
+
+// decisionEvent builds an event carrying a decision's current state
+func decisionEvent(eventType EventType, decision *models.Decision) Event {
+	votes := make(map[string]int, len(decision.Votes))
+	for option, count := range decision.Votes {
+		votes[option] = count
+	}
+
+	var winner *string
+	if decision.Winner != nil {
+		w := *decision.Winner
+		winner = &w
+	}
+
+	return Event{
+		Type:       eventType,
+		ProjectID:  decision.ProjectID,
+		DecisionID: decision.ID,
+		Votes:      votes,
+		State:      string(decision.State),
+		Winner:     winner,
+		Resolution: decision.Resolution,
+		Reason:     decision.ResolutionReason,
+		Timestamp:  time.Now(),
+	}
+}
+
+// projectEvent builds an event carrying a project's current state
+func projectEvent(eventType EventType, project *models.Project) Event {
+	return Event{
+		Type:      eventType,
+		ProjectID: project.ID,
+		State:     string(project.State),
+		Reason:    project.CancelReason,
+		Timestamp: time.Now(),
+	}
+}
@@ -0,0 +1,99 @@
+package project
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bneil/voter/internal/models"
+	"github.com/bneil/voter/internal/storage"
+)
+
+// ReplayTarget selects the point in a project's history to rebuild. A zero
+// Seq or Time places no limit, so the zero target replays the whole history.
+type ReplayTarget struct {
+	Seq  int64     // last event to apply
+	Time time.Time // events recorded after this time are not applied
+}
+
+// includes reports whether an event falls within the target
+func (t ReplayTarget) includes(event *models.HistoryEvent) bool {
+	if t.Seq > 0 && event.Seq > t.Seq {
+		return false
+	}
+	return t.Time.IsZero() || !event.Time.After(t.Time)
+}
+
+// ReplayProject rebuilds a project as it was at a point in its history by
+// folding its events, starting from the latest snapshot before that point.
+// Projects created before history was recorded return ErrNoHistory until
+// they are next saved, which records them as they were stored.
+func (s *Service) ReplayProject(projectID string, target ReplayTarget) (*models.Project, error) {
+	history, ok := s.store.(storage.HistoryStore)
+	if !ok {
+		return nil, fmt.Errorf("%w: the store does not record history", ErrNoHistory)
+	}
+
+	lock := s.projects.For(projectID)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	current, err := s.store.GetProject(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if current.HistorySeq == 0 {
+		return nil, fmt.Errorf("%w: project %s", ErrNoHistory, projectID)
+	}
+
+	// Events past the project's HistorySeq belong to saves that failed
+	if target.Seq == 0 || target.Seq > current.HistorySeq {
+		target.Seq = current.HistorySeq
+	}
+
+	project, err := replayStart(history, projectID, target.Seq, target.Time)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := history.GetHistory(projectID, project.HistorySeq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	// Histories of legacy projects upgraded before they were given a
+	// baseline have nothing to fold their first events into
+	if project.HistorySeq == 0 && len(events) > 0 && events[0].Type != models.HistoryProjectCreated {
+		return nil, fmt.Errorf("%w: project %s has no recorded creation", ErrNoHistory, projectID)
+	}
+	for i := range events {
+		if !target.includes(&events[i]) {
+			break
+		}
+		if err := project.Apply(&events[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if project.HistorySeq == 0 {
+		return nil, fmt.Errorf("%w: project %s did not exist yet", ErrNoHistory, projectID)
+	}
+	return project, nil
+}
+
+// replayStart returns the latest snapshot at or before seq that was taken
+// no later than until, or an empty project to fold the whole history into
+func replayStart(history storage.HistoryStore, projectID string, seq int64, until time.Time) (*models.Project, error) {
+	for seq > 0 {
+		snapshot, err := history.GetSnapshot(projectID, seq)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		if snapshot == nil {
+			break
+		}
+		if until.IsZero() || !snapshot.UpdatedAt.After(until) {
+			return snapshot, nil
+		}
+		seq = snapshot.HistorySeq - 1
+	}
+	return &models.Project{}, nil
+}
@@ -1,12 +1,18 @@
 package project
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/bneil/voter/internal/models"
 )
 
+var (
+	ErrDecisionActive = errors.New("current decision is still active")
+	ErrNoWinner       = errors.New("previous decision has no winner")
+)
+
 // ProgressionManager handles game progression logic
 type ProgressionManager struct {
 	service *Service
@@ -19,42 +25,77 @@ func NewProgressionManager(service *Service) *ProgressionManager {
 	}
 }
 
-// AdvanceProject advances the project to the next decision based on the current winner
-func (pm *ProgressionManager) AdvanceProject(projectID string, nextDecisionDesc string, nextOptions []string) (*models.Decision, error) {
-	project, err := pm.service.GetProject(projectID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get project: %w", err)
-	}
+// AdvanceProject advances the project to the next decision based on the current winner.
+// Once the project has used all of its turns it is completed instead, and a nil
+// decision is returned. Cancelled decisions are skipped over and do not use up
+// a turn, so the next decision retries the turn that was cancelled.
+func (pm *ProgressionManager) AdvanceProject(projectID string, nextDecisionDesc string, nextOptions []string, preconditions ...Precondition) (*models.Decision, error) {
+	_, decision, err := pm.AdvanceProjectWithResult(projectID, nextDecisionDesc, nextOptions, preconditions...)
+	return decision, err
+}
 
-	if !project.CanAcceptVotes() {
-		return nil, ErrProjectNotActive
-	}
+// AdvanceProjectWithResult advances the project like AdvanceProject and also
+// returns the project as saved, whether it was advanced or completed. The
+// checks and the change are made under the project's lock, against the
+// version the preconditions were checked on.
+func (pm *ProgressionManager) AdvanceProjectWithResult(projectID string, nextDecisionDesc string, nextOptions []string, preconditions ...Precondition) (*models.Project, *models.Decision, error) {
+	var saved *models.Project
+	var decision *models.Decision
+	err := pm.service.updateProject(projectID, preconditions, func(project *models.Project) error {
+		if project.State == models.ProjectStatePaused {
+			return ErrProjectPaused
+		}
 
-	// Check if current decision is complete
-	currentDecision := project.GetCurrentDecision()
-	if currentDecision != nil && currentDecision.State == models.DecisionStateVoting {
-		return nil, fmt.Errorf("current decision is still active")
-	}
+		if !project.CanAcceptVotes() {
+			return ErrProjectNotActive
+		}
 
-	// Check if project should end
-	if pm.shouldEndProject(project) {
-		return nil, pm.service.EndProject(projectID)
-	}
+		// Check if current decision is complete
+		if project.GetCurrentDecision() != nil {
+			return ErrDecisionActive
+		}
+
+		// The next turn builds on the previous winner, and the project only
+		// completes once its last turn has one
+		if previous := lastUncancelledDecision(project); previous != nil && previous.Winner == nil {
+			return fmt.Errorf("%w: %s", ErrNoWinner, previous.ID)
+		}
+
+		saved = project
+
+		// Check if project should end
+		if pm.shouldEndProject(project) {
+			return pm.service.completeProject(project)
+		}
 
-	// Start next decision
-	decisionID := fmt.Sprintf("decision_%d", project.CurrentTurn+1)
-	decision, err := pm.service.StartDecision(projectID, decisionID, nextDecisionDesc, nextOptions)
+		// Start next decision
+		var err error
+		decision, err = pm.service.startDecision(project, "", nextDecisionDesc, nextOptions, DecisionConfig{})
+		if err != nil {
+			return fmt.Errorf("failed to start next decision: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to start next decision: %w", err)
+		return nil, nil, err
 	}
+	return saved, decision, nil
+}
 
-	return decision, nil
+// lastUncancelledDecision returns the latest decision that was not cancelled, or nil
+func lastUncancelledDecision(project *models.Project) *models.Decision {
+	for i := len(project.Decisions) - 1; i >= 0; i-- {
+		if project.Decisions[i].State != models.DecisionStateCancelled {
+			return &project.Decisions[i]
+		}
+	}
+	return nil
 }
 
 // shouldEndProject determines if the project should end based on various conditions
 func (pm *ProgressionManager) shouldEndProject(project *models.Project) bool {
 	// End if max turns reached
-	if project.CurrentTurn >= project.MaxTurns {
+	if project.TurnsUsed() >= project.MaxTurns {
 		return true
 	}
 
@@ -134,7 +175,9 @@ func (pm *ProgressionManager) GetProjectProgress(projectID string) (*ProjectProg
 
 		if decision.CompletedAt != nil {
 			decisionProgress.CompletedAt = *decision.CompletedAt
-			decisionProgress.ConsensusTime = decision.CompletedAt.Sub(decision.VotingStarted)
+			if decision.Winner != nil {
+				decisionProgress.ConsensusTime = decision.ConsensusTime()
+			}
 		}
 
 		progress.Decisions = append(progress.Decisions, decisionProgress)
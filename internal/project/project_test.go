@@ -1,8 +1,18 @@
 package project_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/bneil/voter/internal/metrics"
 	"github.com/bneil/voter/internal/models"
 	"github.com/bneil/voter/internal/project"
 	"github.com/bneil/voter/internal/storage"
@@ -12,13 +22,19 @@ import (
 func setupTestServices(t *testing.T) (*project.Service, *storage.JSONProjectStore) {
 	t.Helper()
 
-	store, err := storage.NewJSONProjectStore(t.TempDir())
+	dataDir := t.TempDir()
+	store, err := storage.NewJSONProjectStore(dataDir)
 	if err != nil {
 		t.Fatalf("Failed to create test store: %v", err)
 	}
 
+	voteStore, err := storage.NewJSONVoteStore(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to create test vote store: %v", err)
+	}
+
 	votingService := project.NewVotingService()
-	service := project.NewService(store, votingService)
+	service := project.NewService(store, voteStore, votingService)
 
 	return service, store
 }
@@ -89,12 +105,12 @@ func TestCastVote(t *testing.T) {
 	}
 
 	// Cast votes
-	err = service.CastVote("test-project", decision.ID, "agent1", "A")
+	_, err = service.CastVote("test-project", decision.ID, "agent1", "A")
 	if err != nil {
 		t.Fatalf("Failed to cast vote: %v", err)
 	}
 
-	err = service.CastVote("test-project", decision.ID, "agent2", "A")
+	_, err = service.CastVote("test-project", decision.ID, "agent2", "A")
 	if err != nil {
 		t.Fatalf("Failed to cast vote: %v", err)
 	}
@@ -123,76 +139,1392 @@ func TestCastVote(t *testing.T) {
 	}
 }
 
-func TestEndProject(t *testing.T) {
+func TestCastVoteRecordsVote(t *testing.T) {
+	service, _ := setupTestServices(t)
+
+	_, err := service.CreateProject("test-project", "Test Project", 3, 10)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	decision, err := service.StartDecision("test-project", "decision-1", "Test decision", []string{"A", "B"})
+	if err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+
+	if _, err := service.CastVote("test-project", decision.ID, "agent1", "A"); err != nil {
+		t.Fatalf("Failed to cast vote: %v", err)
+	}
+	if _, err := service.CastVote("test-project", decision.ID, "agent2", "B"); err != nil {
+		t.Fatalf("Failed to cast vote: %v", err)
+	}
+
+	// Rejected votes should not be recorded
+	if _, err := service.CastVote("test-project", decision.ID, "agent3", "Z"); err == nil {
+		t.Fatal("Expected invalid option to be rejected")
+	}
+
+	votes, err := service.GetVotes("test-project")
+	if err != nil {
+		t.Fatalf("Failed to get votes: %v", err)
+	}
+
+	if len(votes) != 2 {
+		t.Fatalf("Expected 2 recorded votes, got %d", len(votes))
+	}
+
+	if votes[0].AgentID != "agent1" || votes[0].Option != "A" {
+		t.Errorf("Expected first vote agent1->A, got %s->%s", votes[0].AgentID, votes[0].Option)
+	}
+
+	if votes[1].AgentID != "agent2" || votes[1].Option != "B" {
+		t.Errorf("Expected second vote agent2->B, got %s->%s", votes[1].AgentID, votes[1].Option)
+	}
+
+	if votes[0].ID == votes[1].ID {
+		t.Error("Expected vote IDs to be unique")
+	}
+
+	if votes[0].DecisionID != decision.ID || votes[0].Timestamp.IsZero() {
+		t.Errorf("Expected vote to reference decision and carry a timestamp, got %+v", votes[0])
+	}
+}
+
+func TestCastVoteRevotePolicy(t *testing.T) {
+	service, _ := setupTestServices(t)
+
+	// Default policy rejects duplicate votes
+	_, err := service.CreateProject("strict", "Strict", 5, 10)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	decision, err := service.StartDecision("strict", "decision-1", "Test decision", []string{"A", "B"})
+	if err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+	if _, err := service.CastVote("strict", decision.ID, "agent1", "A"); err != nil {
+		t.Fatalf("Failed to cast vote: %v", err)
+	}
+	if _, err := service.CastVote("strict", decision.ID, "agent1", "A"); !errors.Is(err, project.ErrDuplicateVote) {
+		t.Errorf("Expected ErrDuplicateVote, got %v", err)
+	}
+
+	// Replace-previous moves the agent's vote
+	settings := models.ProjectSettings{RevotePolicy: models.RevotePolicyReplacePrevious}
+	_, err = service.CreateProjectWithSettings("lenient", "Lenient", 5, 10, settings)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	decision, err = service.StartDecision("lenient", "decision-1", "Test decision", []string{"A", "B"})
+	if err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+	service.CastVote("lenient", decision.ID, "agent1", "A")
+	if _, err := service.CastVote("lenient", decision.ID, "agent1", "B"); err != nil {
+		t.Fatalf("Failed to replace vote: %v", err)
+	}
+
+	status, err := service.GetProjectStatus("lenient")
+	if err != nil {
+		t.Fatalf("Failed to get project status: %v", err)
+	}
+	if status.VoteCounts["A"] != 0 || status.VoteCounts["B"] != 1 {
+		t.Errorf("Expected A=0 B=1, got %v", status.VoteCounts)
+	}
+
+	// Unknown policies are rejected up front
+	settings = models.ProjectSettings{RevotePolicy: "sometimes"}
+	if _, err := service.CreateProjectWithSettings("bad", "Bad", 5, 10, settings); !errors.Is(err, models.ErrInvalidRevotePolicy) {
+		t.Errorf("Expected ErrInvalidRevotePolicy, got %v", err)
+	}
+}
+
+func TestOpenDecision(t *testing.T) {
 	service, _ := setupTestServices(t)
 
-	// Create and complete a project
 	_, err := service.CreateProject("test-project", "Test Project", 2, 10)
 	if err != nil {
 		t.Fatalf("Failed to create project: %v", err)
 	}
 
-	err = service.EndProject("test-project")
+	config := project.DecisionConfig{Open: true}
+	_, decision, err := service.StartDecisionWithConfig("test-project", "decision-1", "Next move?", nil, config)
 	if err != nil {
-		t.Fatalf("Failed to end project: %v", err)
+		t.Fatalf("Failed to start open decision: %v", err)
 	}
 
-	// Check project status
+	for agent, answer := range map[string]string{"agent1": "move disk 1 to C", "agent2": "move disk 1 to B"} {
+		if _, err := service.CastVote("test-project", decision.ID, agent, answer); err != nil {
+			t.Fatalf("Failed to cast vote: %v", err)
+		}
+	}
+	service.CastVote("test-project", decision.ID, "agent3", "move disk 1 to C")
+	service.CastVote("test-project", decision.ID, "agent4", "move disk 1 to C")
+
 	status, err := service.GetProjectStatus("test-project")
 	if err != nil {
 		t.Fatalf("Failed to get project status: %v", err)
 	}
 
-	if status.Project.State != models.ProjectStateCompleted {
-		t.Errorf("Expected project state 'completed', got '%s'", status.Project.State)
+	completed := status.Project.Decisions[0]
+	if len(completed.Options) != 2 {
+		t.Errorf("Expected 2 discovered candidates, got %v", completed.Options)
 	}
+	if completed.Winner == nil || *completed.Winner != "move disk 1 to C" {
+		t.Errorf("Expected 'move disk 1 to C' to win, got %v", completed.Winner)
+	}
+}
 
-	if !status.Project.IsComplete() {
-		t.Error("Expected game to be complete")
+func TestCastVoteCanonicalizesAnswers(t *testing.T) {
+	service, _ := setupTestServices(t)
+
+	settings := models.ProjectSettings{
+		Normalizers: []models.NormalizerSpec{
+			{Type: "unicode"},
+			{Type: "casefold"},
+			{Type: "regex", Pattern: `\s*->\s*`, Replacement: "->"},
+		},
+	}
+	_, err := service.CreateProjectWithSettings("test-project", "Test Project", 3, 10, settings)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
 	}
 
-	if status.Project.CompletedAt == nil || status.Project.CompletedAt.IsZero() {
-		t.Error("Expected completion timestamp to be set")
+	decision, err := service.StartDecision("test-project", "decision-1", "Move", []string{"A->B", "A->C"})
+	if err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+
+	for i, answer := range []string{"A->B", "a -> b", "A→B"} {
+		if _, err := service.CastVote("test-project", decision.ID, fmt.Sprintf("agent%d", i), answer); err != nil {
+			t.Fatalf("Failed to cast vote %q: %v", answer, err)
+		}
+	}
+
+	project, err := service.GetProject("test-project")
+	if err != nil {
+		t.Fatalf("Failed to get project: %v", err)
+	}
+	if winner := project.Decisions[0].Winner; winner == nil || *winner != "A->B" {
+		t.Errorf("Expected A->B to win with all three answers, got %v", winner)
+	}
+
+	votes, err := service.GetVotes("test-project")
+	if err != nil {
+		t.Fatalf("Failed to get votes: %v", err)
+	}
+	if votes[2].RawOption != "A→B" || votes[2].Option != "A->B" {
+		t.Errorf("Expected raw A→B counted as A->B, got %q -> %q", votes[2].RawOption, votes[2].Option)
 	}
 }
 
-func TestStrategicVoting(t *testing.T) {
+func TestCastVoteRedFlags(t *testing.T) {
 	service, _ := setupTestServices(t)
-	enhancedVoting := voting.NewEnhancedVotingService()
-	enhancedVoting.InitializeStrategies()
 
-	// Create project and decision
-	_, err := service.CreateProject("test-project", "Test Project", 2, 10)
+	settings := models.ProjectSettings{
+		RedFlags: models.RedFlagRules{MaxLength: 10},
+	}
+	_, err := service.CreateProjectWithSettings("test-project", "Test Project", 2, 10, settings)
 	if err != nil {
 		t.Fatalf("Failed to create project: %v", err)
 	}
 
-	options := []string{"A", "B", "C"}
-	_, err = service.StartDecision("test-project", "decision-1", "Test decision", options)
+	decision, err := service.StartDecision("test-project", "decision-1", "Move", []string{"A->B", "A->C"})
 	if err != nil {
 		t.Fatalf("Failed to start decision: %v", err)
 	}
 
-	// Get project status
+	_, err = service.CastVote("test-project", decision.ID, "agent1", "A->B because it frees the small disk")
+	if !errors.Is(err, project.ErrVoteFlagged) {
+		t.Fatalf("Expected ErrVoteFlagged, got %v", err)
+	}
+
+	// A flagged agent may still submit a clean answer
+	if _, err := service.CastVote("test-project", decision.ID, "agent1", "A->B"); err != nil {
+		t.Fatalf("Failed to cast vote: %v", err)
+	}
+
 	status, err := service.GetProjectStatus("test-project")
 	if err != nil {
 		t.Fatalf("Failed to get project status: %v", err)
 	}
+	if status.VoteCounts["A->B"] != 1 {
+		t.Errorf("Expected only the clean vote to count, got %d", status.VoteCounts["A->B"])
+	}
+	if status.CurrentDecision.FlaggedVotes != 1 {
+		t.Errorf("Expected 1 flagged vote, got %d", status.CurrentDecision.FlaggedVotes)
+	}
 
-	// Cast strategic vote
-	err = enhancedVoting.CastStrategicVote(status.Project, status.CurrentDecision, "agent1", "consensus")
+	votes, err := service.GetVotes("test-project")
 	if err != nil {
-		t.Fatalf("Failed to cast strategic vote: %v", err)
+		t.Fatalf("Failed to get votes: %v", err)
+	}
+	if len(votes) != 2 || !votes[0].Flagged || votes[0].FlagReason == "" || votes[1].Flagged {
+		t.Errorf("Expected the first of two recorded votes to be flagged with a reason, got %+v", votes)
+	}
+}
+
+func TestVoteBudgetFallback(t *testing.T) {
+	tests := []struct {
+		fallback   models.FallbackAction
+		state      models.DecisionState
+		resolution models.Resolution
+		winner     string
+	}{
+		{models.FallbackPlurality, models.DecisionStateCompleted, models.ResolutionPlurality, "A"},
+		{models.FallbackCancel, models.DecisionStateCancelled, models.ResolutionNoConsensus, ""},
+		{models.FallbackEscalate, models.DecisionStateEscalated, models.ResolutionEscalated, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.fallback), func(t *testing.T) {
+			service, _ := setupTestServices(t)
+
+			var escalated *models.Decision
+			service.SetEscalationHandler(func(project *models.Project, decision *models.Decision) {
+				escalated = decision
+			})
+
+			settings := models.ProjectSettings{MaxVotesPerDecision: 3, BudgetFallback: tt.fallback}
+			_, err := service.CreateProjectWithSettings("test-project", "Test Project", 3, 10, settings)
+			if err != nil {
+				t.Fatalf("Failed to create project: %v", err)
+			}
+
+			decision, err := service.StartDecision("test-project", "decision-1", "Split vote", []string{"A", "B"})
+			if err != nil {
+				t.Fatalf("Failed to start decision: %v", err)
+			}
+
+			for i, option := range []string{"A", "B", "A"} {
+				if _, err := service.CastVote("test-project", decision.ID, fmt.Sprintf("agent%d", i), option); err != nil {
+					t.Fatalf("Failed to cast vote: %v", err)
+				}
+			}
+
+			// The budget is spent, so further votes are refused
+			if _, err := service.CastVote("test-project", decision.ID, "agent9", "A"); err == nil {
+				t.Error("Expected vote after budget exhaustion to fail")
+			}
+
+			project, err := service.GetProject("test-project")
+			if err != nil {
+				t.Fatalf("Failed to get project: %v", err)
+			}
+
+			resolved := project.Decisions[0]
+			if resolved.State != tt.state || resolved.Resolution != tt.resolution {
+				t.Errorf("Expected %s/%s, got %s/%s", tt.state, tt.resolution, resolved.State, resolved.Resolution)
+			}
+			if resolved.ResolutionReason == "" {
+				t.Error("Expected a resolution reason")
+			}
+
+			winner := ""
+			if resolved.Winner != nil {
+				winner = *resolved.Winner
+			}
+			if winner != tt.winner {
+				t.Errorf("Expected winner %q, got %q", tt.winner, winner)
+			}
+
+			if (escalated != nil) != (tt.fallback == models.FallbackEscalate) {
+				t.Errorf("Expected escalation handler to run only for escalate, got %v", escalated)
+			}
+		})
+	}
+}
+
+func TestDecisionDeadline(t *testing.T) {
+	service, _ := setupTestServices(t)
+
+	_, err := service.CreateProject("test-project", "Test Project", 3, 10)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	config := project.DecisionConfig{Timeout: 20 * time.Millisecond}
+	_, decision, err := service.StartDecisionWithConfig("test-project", "decision-1", "Quick", []string{"A", "B"}, config)
+	if err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+	if decision.Deadline == nil {
+		t.Fatal("Expected decision to have a deadline")
+	}
+
+	if _, err := service.CastVote("test-project", decision.ID, "agent1", "B"); err != nil {
+		t.Fatalf("Failed to cast vote: %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if _, err := service.CastVote("test-project", decision.ID, "agent2", "A"); !errors.Is(err, project.ErrVotingClosed) {
+		t.Errorf("Expected ErrVotingClosed after the deadline, got %v", err)
+	}
+
+	p, err := service.GetProject("test-project")
+	if err != nil {
+		t.Fatalf("Failed to get project: %v", err)
+	}
+	expired := p.Decisions[0]
+	if expired.ExpiredAt == nil {
+		t.Error("Expected expired_at to be recorded")
+	}
+	if expired.Resolution != models.ResolutionPlurality || expired.Winner == nil || *expired.Winner != "B" {
+		t.Errorf("Expected plurality winner B, got %s/%v", expired.Resolution, expired.Winner)
+	}
+
+	// Deadlines in the past are rejected
+	past := project.DecisionConfig{Deadline: time.Now().Add(-time.Minute)}
+	if _, _, err := service.StartDecisionWithConfig("test-project", "decision-2", "Late", []string{"A", "B"}, past); !errors.Is(err, project.ErrInvalidDecision) {
+		t.Errorf("Expected ErrInvalidDecision for a past deadline, got %v", err)
+	}
+}
+
+func TestSweepExtendsDecisions(t *testing.T) {
+	service, _ := setupTestServices(t)
+
+	settings := models.ProjectSettings{
+		DecisionTimeout: 20 * time.Millisecond,
+		TimeoutOutcome:  models.FallbackExtend,
+	}
+	_, err := service.CreateProjectWithSettings("test-project", "Test Project", 3, 10, settings)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	decision, err := service.StartDecision("test-project", "decision-1", "Slow", []string{"A", "B"})
+	if err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	results, err := service.SweepExpiredDecisions()
+	if err != nil {
+		t.Fatalf("Failed to sweep: %v", err)
+	}
+	if len(results) != 1 || !results[0].Extended || results[0].DecisionID != decision.ID {
+		t.Fatalf("Expected one extended decision, got %+v", results)
+	}
+
+	// The extended decision still accepts votes
+	if _, err := service.CastVote("test-project", decision.ID, "agent1", "A"); err != nil {
+		t.Errorf("Expected extended decision to accept votes, got %v", err)
+	}
+
+	p, err := service.GetProject("test-project")
+	if err != nil {
+		t.Fatalf("Failed to get project: %v", err)
+	}
+	if p.Decisions[0].Extensions != 1 || p.Decisions[0].ExpiredAt != nil {
+		t.Errorf("Expected one extension and no expiry, got %d/%v", p.Decisions[0].Extensions, p.Decisions[0].ExpiredAt)
+	}
+}
+
+func TestStartDecisionGeneratesIDs(t *testing.T) {
+	service, _ := setupTestServices(t)
+
+	_, err := service.CreateProject("test-project", "Test Project", 1, 10)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	first, err := service.StartDecision("test-project", "", "First", []string{"A", "B"})
+	if err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+	if first.ID != "decision_1" {
+		t.Errorf("Expected decision_1, got %s", first.ID)
+	}
+	if _, err := service.CastVote("test-project", first.ID, "agent1", "A"); err != nil {
+		t.Fatalf("Failed to cast vote: %v", err)
+	}
+
+	// Explicit IDs may not reuse an existing decision's ID
+	if _, err := service.StartDecision("test-project", "decision_1", "Again", []string{"A", "B"}); !errors.Is(err, project.ErrDuplicateDecision) {
+		t.Errorf("Expected ErrDuplicateDecision, got %v", err)
+	}
+
+	second, err := service.StartDecision("test-project", "", "Second", []string{"A", "B"})
+	if err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+	if second.ID != "decision_2" {
+		t.Errorf("Expected decision_2, got %s", second.ID)
+	}
+}
+
+func TestAdvanceProject(t *testing.T) {
+	service, _ := setupTestServices(t)
+	progression := project.NewProgressionManager(service)
+
+	_, err := service.CreateProject("test-project", "Test Project", 1, 2)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	first, err := progression.AdvanceProject("test-project", "Turn one", []string{"A", "B"})
+	if err != nil {
+		t.Fatalf("Failed to advance project: %v", err)
+	}
+
+	// The next turn waits for the current decision to finish
+	if _, err := progression.AdvanceProject("test-project", "Turn two", []string{"A", "B"}); !errors.Is(err, project.ErrDecisionActive) {
+		t.Errorf("Expected ErrDecisionActive, got %v", err)
+	}
+
+	if _, err := service.CastVote("test-project", first.ID, "agent1", "A"); err != nil {
+		t.Fatalf("Failed to cast vote: %v", err)
+	}
+
+	second, err := progression.AdvanceProject("test-project", "Turn two", []string{"A", "B"})
+	if err != nil {
+		t.Fatalf("Failed to advance project: %v", err)
+	}
+	if second.ID != "decision_2" || second.TurnNumber != 2 {
+		t.Errorf("Expected decision_2 on turn 2, got %s on turn %d", second.ID, second.TurnNumber)
+	}
+	if _, err := service.CastVote("test-project", second.ID, "agent1", "B"); err != nil {
+		t.Fatalf("Failed to cast vote: %v", err)
+	}
+
+	// Max turns reached: advancing completes the project
+	decision, err := progression.AdvanceProject("test-project", "Turn three", []string{"A", "B"})
+	if err != nil || decision != nil {
+		t.Fatalf("Expected project to complete, got %v, %v", decision, err)
+	}
+
+	progress, err := progression.GetProjectProgress("test-project")
+	if err != nil {
+		t.Fatalf("Failed to get progress: %v", err)
+	}
+	if progress.State != models.ProjectStateCompleted || progress.CompletedDecisions != 2 {
+		t.Errorf("Expected completed project with 2 decisions, got %s with %d", progress.State, progress.CompletedDecisions)
+	}
+	if progress.Decisions[1].Winner != "B" {
+		t.Errorf("Expected B to win turn two, got %q", progress.Decisions[1].Winner)
+	}
+}
+
+func TestAdvanceAfterCancelledDecision(t *testing.T) {
+	service, _ := setupTestServices(t)
+	progression := project.NewProgressionManager(service)
+
+	if _, err := service.CreateProject("test-project", "Test Project", 1, 2); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	first, err := progression.AdvanceProject("test-project", "Turn one", []string{"A", "B"})
+	if err != nil {
+		t.Fatalf("Failed to advance project: %v", err)
+	}
+
+	// Cancelled decisions do not use up turns, however many there are
+	previous := first
+	for i := 0; i < 2; i++ {
+		if err := service.CancelDecision("test-project", previous.ID, "bad options"); err != nil {
+			t.Fatalf("Failed to cancel decision: %v", err)
+		}
+		retry, err := progression.AdvanceProject("test-project", "Turn one again", []string{"A", "C"})
+		if err != nil {
+			t.Fatalf("Expected to advance past a cancelled decision, got %v", err)
+		}
+		if retry.TurnNumber != 1 || retry.ID == previous.ID {
+			t.Errorf("Expected a new decision retrying turn 1, got %s at turn %d", retry.ID, retry.TurnNumber)
+		}
+		previous = retry
+	}
+
+	if _, err := service.CastVote("test-project", previous.ID, "agent1", "A"); err != nil {
+		t.Fatalf("Failed to vote: %v", err)
+	}
+	last, err := progression.AdvanceProject("test-project", "Turn two", []string{"A", "B"})
+	if err != nil || last.TurnNumber != 2 {
+		t.Fatalf("Expected to advance to turn 2, got %v, %v", last, err)
+	}
+	if err := service.CancelDecision("test-project", last.ID, "bad options"); err != nil {
+		t.Fatalf("Failed to cancel decision: %v", err)
+	}
+
+	// A cancelled last turn is retried rather than completing the project
+	last, err = progression.AdvanceProject("test-project", "Turn two again", []string{"A", "B"})
+	if err != nil || last == nil || last.TurnNumber != 2 {
+		t.Fatalf("Expected to retry turn 2, got %v, %v", last, err)
+	}
+	if _, err := service.CastVote("test-project", last.ID, "agent1", "B"); err != nil {
+		t.Fatalf("Failed to vote: %v", err)
+	}
+
+	if decision, err := progression.AdvanceProject("test-project", "Turn three", []string{"A", "B"}); err != nil || decision != nil {
+		t.Fatalf("Expected project to complete, got %v, %v", decision, err)
+	}
+	p, _ := service.GetProject("test-project")
+	if p.State != models.ProjectStateCompleted {
+		t.Errorf("Expected completed project, got %s", p.State)
+	}
+}
+
+func TestAdvanceDoesNotCompleteWithoutWinner(t *testing.T) {
+	service, _ := setupTestServices(t)
+	progression := project.NewProgressionManager(service)
+
+	settings := models.ProjectSettings{MaxVotesPerDecision: 2, BudgetFallback: models.FallbackEscalate}
+	if _, err := service.CreateProjectWithSettings("test-project", "Test Project", 2, 1, settings); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	decision, err := progression.AdvanceProject("test-project", "Only turn", []string{"A", "B"})
+	if err != nil {
+		t.Fatalf("Failed to advance project: %v", err)
+	}
+	for agent, answer := range map[string]string{"agent1": "A", "agent2": "B"} {
+		if _, err := service.CastVote("test-project", decision.ID, agent, answer); err != nil {
+			t.Fatalf("Failed to vote: %v", err)
+		}
+	}
+
+	// The last turn was escalated, so the project is not completed
+	if _, err := progression.AdvanceProject("test-project", "Next", []string{"A", "B"}); !errors.Is(err, project.ErrNoWinner) {
+		t.Errorf("Expected ErrNoWinner, got %v", err)
+	}
+	p, _ := service.GetProject("test-project")
+	if p.State != models.ProjectStateActive {
+		t.Errorf("Expected the project to stay active, got %s", p.State)
 	}
 
-	// Verify vote was cast by checking the decision directly
-	// (since the enhanced voting modifies the decision in place)
-	totalVotes := 0
-	for _, count := range status.CurrentDecision.Votes {
-		totalVotes += count
+	// Cancelling the escalated decision gives its turn back to be retried
+	if err := service.CancelDecision("test-project", decision.ID, "escalated to a human"); err != nil {
+		t.Fatalf("Failed to cancel escalated decision: %v", err)
+	}
+	retry, err := progression.AdvanceProject("test-project", "Only turn again", []string{"A", "B"})
+	if err != nil || retry.TurnNumber != 1 {
+		t.Fatalf("Expected to retry turn 1, got %v, %v", retry, err)
+	}
+	for _, agent := range []string{"agent1", "agent2"} {
+		if _, err := service.CastVote("test-project", retry.ID, agent, "A"); err != nil {
+			t.Fatalf("Failed to vote: %v", err)
+		}
+	}
+	if next, err := progression.AdvanceProject("test-project", "Next", []string{"A", "B"}); err != nil || next != nil {
+		t.Fatalf("Expected the project to complete, got %v, %v", next, err)
+	}
+	if p, _ := service.GetProject("test-project"); p.State != models.ProjectStateCompleted {
+		t.Errorf("Expected completed project, got %s", p.State)
+	}
+}
+
+func TestPauseResumeProject(t *testing.T) {
+	service, _ := setupTestServices(t)
+
+	_, err := service.CreateProject("test-project", "Test Project", 3, 10)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	config := project.DecisionConfig{Timeout: 50 * time.Millisecond}
+	_, decision, err := service.StartDecisionWithConfig("test-project", "decision-1", "Pick", []string{"A", "B"}, config)
+	if err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+	deadline := *decision.Deadline
+
+	if err := service.PauseProject("test-project"); err != nil {
+		t.Fatalf("Failed to pause project: %v", err)
+	}
+
+	if _, err := service.CastVote("test-project", decision.ID, "agent1", "A"); !errors.Is(err, project.ErrProjectPaused) {
+		t.Errorf("Expected ErrProjectPaused, got %v", err)
+	}
+
+	// The deadline would have passed had the clock kept running
+	time.Sleep(60 * time.Millisecond)
+
+	if err := service.ResumeProject("test-project"); err != nil {
+		t.Fatalf("Failed to resume project: %v", err)
+	}
+	if err := service.ResumeProject("test-project"); !errors.Is(err, project.ErrProjectNotPaused) {
+		t.Errorf("Expected ErrProjectNotPaused resuming an active project, got %v", err)
+	}
+
+	if _, err := service.CastVote("test-project", decision.ID, "agent1", "A"); err != nil {
+		t.Fatalf("Expected vote to be accepted after resume, got %v", err)
+	}
+
+	p, err := service.GetProject("test-project")
+	if err != nil {
+		t.Fatalf("Failed to get project: %v", err)
+	}
+	if p.State != models.ProjectStateActive || p.PausedAt != nil {
+		t.Errorf("Expected active project with no pause time, got %s/%v", p.State, p.PausedAt)
+	}
+	current := p.Decisions[0]
+	if current.PausedDuration < 60*time.Millisecond {
+		t.Errorf("Expected paused duration of at least 60ms, got %v", current.PausedDuration)
+	}
+	if !current.Deadline.After(deadline) {
+		t.Errorf("Expected deadline to be pushed back from %v, got %v", deadline, current.Deadline)
+	}
+}
+
+func TestCancelDecision(t *testing.T) {
+	service, _ := setupTestServices(t)
+
+	_, err := service.CreateProject("test-project", "Test Project", 3, 10)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	decision, err := service.StartDecision("test-project", "decision-1", "Pick", []string{"A", "B"})
+	if err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+
+	if err := service.CancelDecision("test-project", decision.ID, "  "); !errors.Is(err, project.ErrReasonRequired) {
+		t.Errorf("Expected ErrReasonRequired, got %v", err)
+	}
+	if err := service.CancelDecision("test-project", "missing", "wrong options"); !errors.Is(err, project.ErrDecisionNotFound) {
+		t.Errorf("Expected ErrDecisionNotFound, got %v", err)
+	}
+	if err := service.CancelDecision("test-project", decision.ID, "wrong options"); err != nil {
+		t.Fatalf("Failed to cancel decision: %v", err)
+	}
+	if err := service.CancelDecision("test-project", decision.ID, "again"); !errors.Is(err, project.ErrVotingClosed) {
+		t.Errorf("Expected ErrVotingClosed, got %v", err)
+	}
+
+	p, err := service.GetProject("test-project")
+	if err != nil {
+		t.Fatalf("Failed to get project: %v", err)
+	}
+	cancelled := p.Decisions[0]
+	if cancelled.State != models.DecisionStateCancelled || cancelled.Resolution != models.ResolutionCancelled {
+		t.Errorf("Expected cancelled decision, got %s/%s", cancelled.State, cancelled.Resolution)
+	}
+	if cancelled.ResolutionReason != "wrong options" {
+		t.Errorf("Expected reason to be recorded, got %q", cancelled.ResolutionReason)
+	}
+
+	// The project stays active and can start another decision
+	if _, err := service.StartDecision("test-project", "decision-2", "Pick again", []string{"C", "D"}); err != nil {
+		t.Errorf("Expected to start a new decision, got %v", err)
+	}
+}
+
+func TestCancelProject(t *testing.T) {
+	service, _ := setupTestServices(t)
+
+	_, err := service.CreateProject("test-project", "Test Project", 3, 10)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	if _, err := service.StartDecision("test-project", "decision-1", "Pick", []string{"A", "B"}); err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+
+	if err := service.CancelProject("test-project", ""); !errors.Is(err, project.ErrReasonRequired) {
+		t.Errorf("Expected ErrReasonRequired, got %v", err)
+	}
+	if err := service.CancelProject("test-project", "agents misconfigured"); err != nil {
+		t.Fatalf("Failed to cancel project: %v", err)
+	}
+
+	p, err := service.GetProject("test-project")
+	if err != nil {
+		t.Fatalf("Failed to get project: %v", err)
+	}
+	if p.State != models.ProjectStateCancelled || p.CancelReason != "agents misconfigured" {
+		t.Errorf("Expected cancelled project with reason, got %s/%q", p.State, p.CancelReason)
+	}
+	if p.Decisions[0].Resolution != models.ResolutionCancelled {
+		t.Errorf("Expected open decision to be cancelled, got %s", p.Decisions[0].Resolution)
+	}
+
+	score := metrics.NewScorer().CalculateProjectScore(p)
+	if score == nil || !score.Cancelled || score.CompletionBonus != 0 {
+		t.Errorf("Expected a cancelled score with no completion bonus, got %+v", score)
+	}
+
+	if err := service.CancelProject("test-project", "again"); err == nil {
+		t.Error("Expected cancelling a finished project to fail")
+	}
+}
+
+func TestEndPausedProjectClearsPause(t *testing.T) {
+	service, _ := setupTestServices(t)
+
+	for _, id := range []string{"ended", "cancelled"} {
+		if _, err := service.CreateProject(id, "Test Project", 3, 10); err != nil {
+			t.Fatalf("Failed to create project: %v", err)
+		}
+		if err := service.PauseProject(id); err != nil {
+			t.Fatalf("Failed to pause project: %v", err)
+		}
+	}
+	if err := service.EndProject("ended"); err != nil {
+		t.Fatalf("Failed to end project: %v", err)
+	}
+	if err := service.CancelProject("cancelled", "no longer needed"); err != nil {
+		t.Fatalf("Failed to cancel project: %v", err)
+	}
+
+	for _, id := range []string{"ended", "cancelled"} {
+		p, err := service.GetProject(id)
+		if err != nil {
+			t.Fatalf("Failed to get project: %v", err)
+		}
+		if p.PausedAt != nil {
+			t.Errorf("%s: expected no pause time once finished, got %v", id, p.PausedAt)
+		}
+
+		replayed, err := service.ReplayProject(id, project.ReplayTarget{})
+		if err != nil {
+			t.Fatalf("Failed to replay project: %v", err)
+		}
+		if replayed.PausedAt != nil {
+			t.Errorf("%s: expected no pause time after replay, got %v", id, replayed.PausedAt)
+		}
+	}
+}
+
+func TestServiceEvents(t *testing.T) {
+	service, _ := setupTestServices(t)
+
+	_, err := service.CreateProject("test-project", "Test Project", 1, 10)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	events, cancel := service.Subscribe("test-project")
+	defer cancel()
+	other, cancelOther := service.Subscribe("other-project")
+	defer cancelOther()
+
+	if _, err := service.StartDecision("test-project", "decision-1", "Pick", []string{"A", "B"}); err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+	if _, err := service.CastVote("test-project", "decision-1", "agent1", "A"); err != nil {
+		t.Fatalf("Failed to cast vote: %v", err)
+	}
+	if err := service.EndProject("test-project"); err != nil {
+		t.Fatalf("Failed to end project: %v", err)
+	}
+
+	expected := []project.EventType{
+		project.EventDecisionStarted,
+		project.EventVoteCast,
+		project.EventDecisionCompleted,
+		project.EventProjectCompleted,
+	}
+	for _, eventType := range expected {
+		select {
+		case event := <-events:
+			if event.Type != eventType {
+				t.Fatalf("Expected %s event, got %s", eventType, event.Type)
+			}
+			if event.Type == project.EventDecisionCompleted && (event.Winner == nil || *event.Winner != "A") {
+				t.Errorf("Expected completed decision to carry winner A, got %v", event.Winner)
+			}
+			if event.Type == project.EventVoteCast && (event.AgentID != "agent1" || event.Votes["A"] != 1) {
+				t.Errorf("Expected vote event for agent1 with tally, got %+v", event)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for %s event", eventType)
+		}
+	}
+
+	select {
+	case event := <-other:
+		t.Errorf("Expected no events for another project, got %s", event.Type)
+	default:
+	}
+
+	cancel()
+	if _, ok := <-events; ok {
+		t.Error("Expected channel to be closed after cancel")
+	}
+}
+
+func TestEndProject(t *testing.T) {
+	service, _ := setupTestServices(t)
+
+	// Create and complete a project
+	_, err := service.CreateProject("test-project", "Test Project", 2, 10)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	err = service.EndProject("test-project")
+	if err != nil {
+		t.Fatalf("Failed to end project: %v", err)
+	}
+
+	// Check project status
+	status, err := service.GetProjectStatus("test-project")
+	if err != nil {
+		t.Fatalf("Failed to get project status: %v", err)
+	}
+
+	if status.Project.State != models.ProjectStateCompleted {
+		t.Errorf("Expected project state 'completed', got '%s'", status.Project.State)
+	}
+
+	if !status.Project.IsComplete() {
+		t.Error("Expected game to be complete")
+	}
+
+	if status.Project.CompletedAt == nil || status.Project.CompletedAt.IsZero() {
+		t.Error("Expected completion timestamp to be set")
+	}
+}
+
+func TestStrategicVoting(t *testing.T) {
+	service, _ := setupTestServices(t)
+	enhancedVoting := voting.NewEnhancedVotingService()
+	enhancedVoting.InitializeStrategies()
+
+	// Create project and decision
+	_, err := service.CreateProject("test-project", "Test Project", 2, 10)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	options := []string{"A", "B", "C"}
+	_, err = service.StartDecision("test-project", "decision-1", "Test decision", options)
+	if err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+
+	if _, err := service.CastVote("test-project", "decision-1", "agent1", "B"); err != nil {
+		t.Fatalf("Failed to cast vote: %v", err)
+	}
+
+	// The consensus strategy follows the leader, which gives B a 2-vote lead
+	answer, err := service.CastStrategicVote("test-project", "decision-1", "agent2", "consensus", enhancedVoting.ChooseVote)
+	if err != nil {
+		t.Fatalf("Failed to cast strategic vote: %v", err)
+	}
+	if answer != "B" {
+		t.Errorf("Expected the consensus strategy to pick B, got %s", answer)
+	}
+
+	// The vote is saved and counted by the K-ahead check
+	status, err := service.GetProjectStatus("test-project")
+	if err != nil {
+		t.Fatalf("Failed to get project status: %v", err)
+	}
+	decision := status.Project.FindDecision("decision-1")
+	if decision.State != models.DecisionStateCompleted || decision.Winner == nil || *decision.Winner != "B" {
+		t.Errorf("Expected B to win after the strategic vote, got state %s", decision.State)
+	}
+	if status.Project.Metrics.TotalDecisions != 1 || status.Project.Metrics.TotalVotes != 2 {
+		t.Errorf("Expected metrics for 1 decision and 2 votes, got %+v", status.Project.Metrics)
+	}
+
+	votes, err := service.GetVotes("test-project")
+	if err != nil {
+		t.Fatalf("Failed to get votes: %v", err)
+	}
+	if len(votes) != 2 || votes[1].Strategy != "consensus" {
+		t.Errorf("Expected the strategic vote to be recorded with its strategy, got %d votes", len(votes))
+	}
+
+	if _, err := service.CastStrategicVote("test-project", "decision-1", "agent3", "consensus", enhancedVoting.ChooseVote); !errors.Is(err, project.ErrVotingClosed) {
+		t.Errorf("Expected ErrVotingClosed after consensus, got %v", err)
+	}
+}
+
+func TestSimulateVotingStopsAtConsensus(t *testing.T) {
+	service, _ := setupTestServices(t)
+
+	if _, err := service.CreateProject("sim", "Simulation", 3, 10); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := service.StartDecision("sim", "decision-1", "Pick", []string{"A", "B"}); err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+	if _, err := service.CastVote("sim", "decision-1", "agent_0", "A"); err != nil {
+		t.Fatalf("Failed to cast vote: %v", err)
+	}
+
+	alwaysA := func(strategy string, p *models.Project, d *models.Decision, agentID string) string {
+		return "A"
+	}
+
+	result, err := service.SimulateVoting("sim", "decision-1", 10, []string{"fixed"}, alwaysA)
+	if err != nil {
+		t.Fatalf("Failed to simulate voting: %v", err)
+	}
+
+	// agent_0 already voted, so two more votes give A a 3-vote lead
+	if result.VotesCast != 2 || !result.Resolved {
+		t.Errorf("Expected the simulation to stop after 2 votes, got %d (resolved %t)", result.VotesCast, result.Resolved)
+	}
+
+	saved, err := service.GetProject("sim")
+	if err != nil {
+		t.Fatalf("Failed to get project: %v", err)
+	}
+	decision := saved.FindDecision("decision-1")
+	if decision.Winner == nil || *decision.Winner != "A" {
+		t.Errorf("Expected the saved decision to have winner A, got %v", decision.Winner)
+	}
+	if !decision.HasVoted("agent_1") || !decision.HasVoted("agent_2") || decision.HasVoted("agent_3") {
+		t.Error("Expected simulated agents agent_1 and agent_2 to have voted")
+	}
+}
+
+// racingStore runs race just before the next conditional save, standing in
+// for another process that saves the project first. It cannot lock projects,
+// so votes through it rely on retries.
+type racingStore struct {
+	storage.ProjectStore
+	race func()
+}
+
+func (s *racingStore) SaveProjectIfVersion(p *models.Project, expected int64, events ...models.HistoryEvent) error {
+	if race := s.race; race != nil {
+		s.race = nil
+		race()
+	}
+	return s.ProjectStore.SaveProjectIfVersion(p, expected, events...)
+}
+
+func TestCastVoteRetriesOnConflict(t *testing.T) {
+	rival, store := setupTestServices(t)
+	voteStore, err := storage.NewJSONVoteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create vote store: %v", err)
+	}
+	racing := &racingStore{ProjectStore: store}
+	service := project.NewService(racing, voteStore, project.NewVotingService())
+
+	if _, err := service.CreateProject("race", "Race", 5, 10); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := service.StartDecision("race", "decision-1", "Race", []string{"A", "B"}); err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+
+	racing.race = func() {
+		if _, err := rival.CastVote("race", "decision-1", "rival", "B"); err != nil {
+			t.Errorf("Rival vote failed: %v", err)
+		}
+	}
+	if _, err := service.CastVote("race", "decision-1", "agent1", "A"); err != nil {
+		t.Fatalf("Expected the vote to be retried, got %v", err)
+	}
+
+	saved, err := store.GetProject("race")
+	if err != nil {
+		t.Fatalf("Failed to get project: %v", err)
+	}
+	decision := saved.FindDecision("decision-1")
+	if decision.Votes["A"] != 1 || decision.Votes["B"] != 1 {
+		t.Errorf("Expected both votes to be kept, got %v", decision.Votes)
+	}
+	if saved.Version != 4 {
+		t.Errorf("Expected version 4, got %d", saved.Version)
+	}
+
+	// A vote made conditional on the version it loaded is not retried
+	racing.race = func() {
+		rival.CastVote("race", "decision-1", "rival2", "B")
+	}
+	_, err = service.CastVote("race", "decision-1", "agent2", "A", project.IfVersion(saved.Version))
+	if !errors.Is(err, project.ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict, got %v", err)
+	}
+}
+
+func TestIfVersion(t *testing.T) {
+	service, _ := setupTestServices(t)
+
+	created, err := service.CreateProject("demo", "Demo", 2, 10)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if created.Version != 1 {
+		t.Errorf("Expected a new project at version 1, got %d", created.Version)
+	}
+
+	if err := service.PauseProject("demo", project.IfVersion(2)); !errors.Is(err, project.ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict, got %v", err)
+	}
+	if status, _ := service.GetProjectStatus("demo"); status.Project.State != models.ProjectStateActive {
+		t.Errorf("Expected the project to be left active, got %s", status.Project.State)
+	}
+
+	if err := service.PauseProject("demo", project.IfVersion(1)); err != nil {
+		t.Fatalf("Failed to pause project at its current version: %v", err)
+	}
+	status, err := service.GetProjectStatus("demo")
+	if err != nil {
+		t.Fatalf("Failed to get project status: %v", err)
+	}
+	if status.Project.State != models.ProjectStatePaused || status.Project.Version != 2 {
+		t.Errorf("Expected a paused project at version 2, got %s at %d", status.Project.State, status.Project.Version)
+	}
+}
+
+func TestAdvanceProjectIfVersion(t *testing.T) {
+	service, _ := setupTestServices(t)
+	progression := project.NewProgressionManager(service)
+
+	if _, err := service.CreateProject("demo", "Demo", 1, 10); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if err := service.PauseProject("demo"); err != nil {
+		t.Fatalf("Failed to pause project: %v", err)
+	}
+
+	// The precondition is checked on the version the advance would change
+	if _, err := progression.AdvanceProject("demo", "Pick", []string{"A", "B"}, project.IfVersion(1)); !errors.Is(err, project.ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict for a stale version, got %v", err)
+	}
+	if err := service.ResumeProject("demo"); err != nil {
+		t.Fatalf("Failed to resume project: %v", err)
+	}
+
+	saved, decision, err := progression.AdvanceProjectWithResult("demo", "Pick", []string{"A", "B"}, project.IfVersion(3))
+	if err != nil {
+		t.Fatalf("Failed to advance project at its current version: %v", err)
+	}
+	if saved.Version != 4 || saved.FindDecision(decision.ID) == nil {
+		t.Errorf("Expected the saved project at version 4 with the new decision, got version %d", saved.Version)
+	}
+}
+
+func TestReplayProject(t *testing.T) {
+	service, store := setupTestServices(t)
+
+	settings := models.ProjectSettings{RedFlags: models.RedFlagRules{BannedTokens: []string{"??"}}}
+	if _, err := service.CreateProjectWithSettings("history", "History", 2, 10, settings); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := service.StartDecision("history", "", "Pick", []string{"A", "B"}); err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+	if _, err := service.CastVote("history", "decision_1", "agent1", "A"); err != nil {
+		t.Fatalf("Failed to cast vote: %v", err)
+	}
+	afterFirstVote, _ := service.GetProject("history")
+	if _, err := service.CastVote("history", "decision_1", "agent2", "??"); !errors.Is(err, project.ErrVoteFlagged) {
+		t.Fatalf("Expected ErrVoteFlagged, got %v", err)
+	}
+	if _, err := service.CastVote("history", "decision_1", "agent3", "A"); err != nil {
+		t.Fatalf("Failed to cast vote: %v", err)
+	}
+	if err := service.PauseProject("history"); err != nil {
+		t.Fatalf("Failed to pause project: %v", err)
+	}
+	if err := service.ResumeProject("history"); err != nil {
+		t.Fatalf("Failed to resume project: %v", err)
+	}
+	if _, err := service.StartDecision("history", "", "Next", []string{"X", "Y"}); err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+	if _, err := service.CastVote("history", "decision_2", "agent1", "X"); err != nil {
+		t.Fatalf("Failed to cast vote: %v", err)
+	}
+	if err := service.CancelProject("history", "done"); err != nil {
+		t.Fatalf("Failed to cancel project: %v", err)
+	}
+
+	// Folding the whole history rebuilds the stored project exactly
+	stored, _ := service.GetProject("history")
+	replayed, err := service.ReplayProject("history", project.ReplayTarget{})
+	if err != nil {
+		t.Fatalf("Failed to replay project: %v", err)
+	}
+	want, _ := json.Marshal(stored)
+	got, _ := json.Marshal(replayed)
+	if !bytes.Equal(got, want) {
+		t.Errorf("Expected the replayed project to match the stored one\nwant %s\ngot  %s", want, got)
+	}
+
+	// Earlier points in the history show the state at the time
+	early, err := service.ReplayProject("history", project.ReplayTarget{Seq: afterFirstVote.HistorySeq})
+	if err != nil {
+		t.Fatalf("Failed to replay project: %v", err)
+	}
+	if early.Version != afterFirstVote.Version || len(early.Decisions) != 1 || early.Decisions[0].Votes["A"] != 1 {
+		t.Errorf("Expected one vote at version %d, got %+v at %d", afterFirstVote.Version, early.Decisions, early.Version)
+	}
+	byTime, err := service.ReplayProject("history", project.ReplayTarget{Time: afterFirstVote.UpdatedAt})
+	if err != nil || byTime.HistorySeq != afterFirstVote.HistorySeq {
+		t.Errorf("Expected replay until %v to stop at event %d, got %v (%v)", afterFirstVote.UpdatedAt, afterFirstVote.HistorySeq, byTime, err)
+	}
+
+	if _, err := service.ReplayProject("history", project.ReplayTarget{Time: stored.CreatedAt.Add(-time.Second)}); !errors.Is(err, project.ErrNoHistory) {
+		t.Errorf("Expected ErrNoHistory before the project was created, got %v", err)
+	}
+	if err := store.SaveProject(models.NewProject("legacy", "Legacy", 2, 10)); err != nil {
+		t.Fatalf("Failed to save project: %v", err)
+	}
+	if _, err := service.ReplayProject("legacy", project.ReplayTarget{}); !errors.Is(err, project.ErrNoHistory) {
+		t.Errorf("Expected ErrNoHistory for a project saved without history, got %v", err)
+	}
+}
+
+func TestReplayUpgradedLegacyProject(t *testing.T) {
+	service, store := setupTestServices(t)
+
+	if err := store.SaveProject(models.NewProject("legacy", "Legacy", 2, 10)); err != nil {
+		t.Fatalf("Failed to save project: %v", err)
+	}
+	if _, err := service.StartDecision("legacy", "", "Pick", []string{"A", "B"}); err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+
+	// The first save with history records the project as it was stored
+	replayed, err := service.ReplayProject("legacy", project.ReplayTarget{})
+	if err != nil {
+		t.Fatalf("Failed to replay upgraded project: %v", err)
+	}
+	current, _ := service.GetProject("legacy")
+	if replayed.Version != current.Version || replayed.HistorySeq != 2 || len(replayed.Decisions) != 1 {
+		t.Errorf("Expected the current project at event 2, got version %d at %d with %d decisions", replayed.Version, replayed.HistorySeq, len(replayed.Decisions))
+	}
+
+	baseline, err := service.ReplayProject("legacy", project.ReplayTarget{Seq: 1})
+	if err != nil {
+		t.Fatalf("Failed to replay baseline: %v", err)
+	}
+	if baseline.Version != 1 || baseline.Name != "Legacy" || len(baseline.Decisions) != 0 {
+		t.Errorf("Expected the legacy project at version 1 with no decisions, got %+v", baseline)
+	}
+}
+
+func TestReplayProjectFromSnapshot(t *testing.T) {
+	service, store := setupTestServices(t)
+
+	settings := models.ProjectSettings{RevotePolicy: models.RevotePolicyAllowMultiple}
+	if _, err := service.CreateProjectWithSettings("long", "Long", 1000, 10, settings); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := service.StartDecision("long", "", "Pick", []string{"A", "B"}); err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+	for i := 0; i < storage.SnapshotInterval+20; i++ {
+		if _, err := service.CastVote("long", "decision_1", "agent1", []string{"A", "B"}[i%2]); err != nil {
+			t.Fatalf("Failed to cast vote: %v", err)
+		}
+	}
+
+	// Replays after the snapshot fold only the events since it
+	snapshot, err := store.GetSnapshot("long", storage.SnapshotInterval+10)
+	if err != nil || snapshot == nil {
+		t.Fatalf("Expected a snapshot, got %v (%v)", snapshot, err)
+	}
+	replayed, err := service.ReplayProject("long", project.ReplayTarget{Seq: storage.SnapshotInterval + 10})
+	if err != nil {
+		t.Fatalf("Failed to replay project: %v", err)
+	}
+	votes := replayed.Decisions[0].Votes
+	if replayed.HistorySeq != storage.SnapshotInterval+10 || votes["A"]+votes["B"] != storage.SnapshotInterval+8 {
+		t.Errorf("Expected %d votes at event %d, got %v at %d", storage.SnapshotInterval+8, storage.SnapshotInterval+10, votes, replayed.HistorySeq)
+	}
+
+	// Replays before it fold from the start
+	early, err := service.ReplayProject("long", project.ReplayTarget{Seq: 12})
+	if err != nil {
+		t.Fatalf("Failed to replay project: %v", err)
+	}
+	if votes := early.Decisions[0].Votes; votes["A"] != 5 || votes["B"] != 5 {
+		t.Errorf("Expected 5 votes each at event 12, got %v", votes)
+	}
+}
+
+func TestConcurrentVotes(t *testing.T) {
+	service, _ := setupTestServices(t)
+
+	projects := []string{"alpha", "beta", "gamma"}
+	for _, id := range projects {
+		if _, err := service.CreateProject(id, id, 1000, 10); err != nil {
+			t.Fatalf("Failed to create project: %v", err)
+		}
+		if _, err := service.StartDecision(id, "decision-1", "Pick", []string{"A", "B"}); err != nil {
+			t.Fatalf("Failed to start decision: %v", err)
+		}
+	}
+
+	// Several goroutines per project, so votes race both within a project
+	// and across projects
+	const goroutines, votes = 4, 10
+	var wg sync.WaitGroup
+	for _, id := range projects {
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func(id string, g int) {
+				defer wg.Done()
+				for i := 0; i < votes; i++ {
+					agentID := fmt.Sprintf("agent_%d_%d", g, i)
+					if _, err := service.CastVote(id, "decision-1", agentID, "A"); err != nil {
+						t.Errorf("Failed to cast vote for %s in %s: %v", agentID, id, err)
+					}
+				}
+			}(id, g)
+		}
+	}
+	wg.Wait()
+
+	for _, id := range projects {
+		status, err := service.GetProjectStatus(id)
+		if err != nil {
+			t.Fatalf("Failed to get project status: %v", err)
+		}
+		if status.VoteCounts["A"] != goroutines*votes {
+			t.Errorf("Expected %d votes in %s, got %d", goroutines*votes, id, status.VoteCounts["A"])
+		}
+	}
+}
+
+// BenchmarkConcurrentVotes measures vote throughput with parallel voters
+// spread over a growing number of projects. With one project every vote is
+// serialized; with more, votes on different projects proceed in parallel.
+func BenchmarkConcurrentVotes(b *testing.B) {
+	for _, projectCount := range []int{1, 8, 64} {
+		b.Run(fmt.Sprintf("projects=%d", projectCount), func(b *testing.B) {
+			dataDir := b.TempDir()
+			store, err := storage.NewJSONProjectStore(dataDir)
+			if err != nil {
+				b.Fatalf("Failed to create store: %v", err)
+			}
+			voteStore, err := storage.NewJSONVoteStore(dataDir)
+			if err != nil {
+				b.Fatalf("Failed to create vote store: %v", err)
+			}
+			service := project.NewService(store, voteStore, project.NewVotingService())
+
+			// Each voter re-votes under one agent ID so decisions stay small
+			settings := models.ProjectSettings{RevotePolicy: models.RevotePolicyAllowMultiple}
+			for i := 0; i < projectCount; i++ {
+				id := fmt.Sprintf("project_%d", i)
+				if _, err := service.CreateProjectWithSettings(id, id, 1<<30, 10, settings); err != nil {
+					b.Fatalf("Failed to create project: %v", err)
+				}
+				if _, err := service.StartDecision(id, "decision-1", "Pick", []string{"A", "B"}); err != nil {
+					b.Fatalf("Failed to start decision: %v", err)
+				}
+			}
+
+			var voters atomic.Int64
+			b.SetParallelism(4)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				voter := int(voters.Add(1) - 1)
+				id := fmt.Sprintf("project_%d", voter%projectCount)
+				agentID := fmt.Sprintf("agent_%d", voter)
+				for pb.Next() {
+					if _, err := service.CastVote(id, "decision-1", agentID, "A"); err != nil {
+						b.Errorf("Failed to cast vote: %v", err)
+						return
+					}
+				}
+			})
+			b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "votes/s")
+		})
+	}
+}
+
+// stressWorkerEnv holds the data directory when the test binary is re-run as
+// a vote-casting worker by TestConcurrentProcessesSerializeVotes
+const stressWorkerEnv = "VOTER_STRESS_WORKER_DIR"
+
+// Enough workers that votes collide constantly; a vote that is not serialized
+// by the project lock fails with ErrVersionConflict and fails its worker
+const (
+	stressWorkers = 30
+	stressVotes   = 15
+)
+
+func TestConcurrentProcessesSerializeVotes(t *testing.T) {
+	if dir := os.Getenv(stressWorkerEnv); dir != "" {
+		runStressWorker(t, dir)
+		return
+	}
+	if testing.Short() {
+		t.Skip("spawns worker processes")
+	}
+
+	dataDir := t.TempDir()
+	store, err := storage.NewJSONProjectStore(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	voteStore, err := storage.NewJSONVoteStore(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to create vote store: %v", err)
+	}
+	service := project.NewService(store, voteStore, project.NewVotingService())
+
+	// K is out of reach so every vote stays on the one decision
+	if _, err := service.CreateProject("stress", "Stress", 1000, 10); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := service.StartDecision("stress", "decision-1", "Stress decision", []string{"A", "B"}); err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+
+	workers := make([]*exec.Cmd, stressWorkers)
+	outputs := make([]*bytes.Buffer, stressWorkers)
+	for i := range workers {
+		outputs[i] = &bytes.Buffer{}
+		cmd := exec.Command(os.Args[0], "-test.run=^TestConcurrentProcessesSerializeVotes$")
+		cmd.Env = append(os.Environ(), stressWorkerEnv+"="+dataDir, fmt.Sprintf("VOTER_STRESS_WORKER_ID=%d", i))
+		cmd.Stdout = outputs[i]
+		cmd.Stderr = outputs[i]
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("Failed to start worker %d: %v", i, err)
+		}
+		workers[i] = cmd
+	}
+	for i, cmd := range workers {
+		if err := cmd.Wait(); err != nil {
+			t.Errorf("Worker %d failed: %v\n%s", i, err, outputs[i])
+		}
+	}
+
+	saved, err := store.GetProject("stress")
+	if err != nil {
+		t.Fatalf("Failed to get project: %v", err)
+	}
+	decision := saved.FindDecision("decision-1")
+	if total := decision.Votes["A"] + decision.Votes["B"]; total != stressWorkers*stressVotes {
+		t.Errorf("Expected %d votes on the decision, got %d", stressWorkers*stressVotes, total)
+	}
+
+	votes, err := service.GetVotes("stress")
+	if err != nil {
+		t.Fatalf("Failed to get votes: %v", err)
+	}
+	if len(votes) != stressWorkers*stressVotes {
+		t.Errorf("Expected %d vote records, got %d", stressWorkers*stressVotes, len(votes))
+	}
+}
+
+// runStressWorker casts votes as one of several processes sharing dir
+func runStressWorker(t *testing.T, dir string) {
+	store, err := storage.NewJSONProjectStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	voteStore, err := storage.NewJSONVoteStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create vote store: %v", err)
 	}
+	service := project.NewService(store, voteStore, project.NewVotingService())
 
-	if totalVotes != 1 {
-		t.Errorf("Expected 1 total vote, got %d", totalVotes)
+	worker := os.Getenv("VOTER_STRESS_WORKER_ID")
+	options := []string{"A", "B"}
+	for i := 0; i < stressVotes; i++ {
+		agentID := fmt.Sprintf("worker%s_agent%d", worker, i)
+		_, err := service.CastVote("stress", "decision-1", agentID, options[i%2])
+		if errors.Is(err, project.ErrVersionConflict) {
+			t.Fatalf("Vote for %s was not serialized: %v", agentID, err)
+		}
+		if err != nil {
+			t.Fatalf("Failed to cast vote for %s: %v", agentID, err)
+		}
 	}
 }
@@ -3,45 +3,248 @@ package project
 import (
 	"errors"
 	"fmt"
+	"math/rand"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/bneil/voter/internal/models"
 	"github.com/bneil/voter/internal/storage"
+	"github.com/bneil/voter/internal/voting"
 )
 
 var (
-	ErrProjectNotFound  = errors.New("project not found")
-	ErrProjectNotActive = errors.New("project is not active")
-	ErrInvalidDecision  = errors.New("invalid decision")
-	ErrDecisionNotFound = errors.New("decision not found")
-	ErrVotingClosed     = errors.New("voting is closed")
-	ErrInvalidOption    = errors.New("invalid voting option")
+	ErrProjectNotFound   = storage.ErrProjectNotFound
+	ErrCorruptProject    = storage.ErrCorruptProject
+	ErrLockTimeout       = storage.ErrLockTimeout
+	ErrVersionConflict   = storage.ErrVersionConflict
+	ErrInvalidHistory    = models.ErrInvalidHistory
+	ErrNoHistory         = errors.New("project has no recorded history")
+	ErrProjectExists     = errors.New("project already exists")
+	ErrInvalidSettings   = errors.New("invalid project settings")
+	ErrProjectNotActive  = errors.New("project is not active")
+	ErrProjectPaused     = errors.New("project is paused")
+	ErrProjectNotPaused  = errors.New("project is not paused")
+	ErrReasonRequired    = errors.New("cancellation reason is required")
+	ErrInvalidDecision   = errors.New("invalid decision")
+	ErrDecisionNotFound  = errors.New("decision not found")
+	ErrDuplicateDecision = errors.New("decision ID already exists")
+	ErrVotingClosed      = errors.New("voting is closed")
+	ErrInvalidOption     = errors.New("invalid voting option")
+	ErrDuplicateVote     = models.ErrDuplicateVote
+	ErrVoteFlagged       = errors.New("vote red-flagged")
 )
 
+// EscalationHandler is notified when a decision is escalated instead of resolved
+type EscalationHandler func(project *models.Project, decision *models.Decision)
+
+// DecisionHandler is notified when a decision stops accepting votes
+type DecisionHandler func(project *models.Project, decision *models.Decision)
+
+// CompletionHandler is notified when a project is completed or cancelled
+type CompletionHandler func(project *models.Project)
+
+// maxVoteAttempts bounds how often a vote is retried after losing a race with
+// another writer, on stores that cannot lock projects
+const maxVoteAttempts = 10
+
+// maxConflictBackoff caps the wait between vote attempts
+const maxConflictBackoff = 50 * time.Millisecond
+
+// Precondition is checked against a project after it is loaded and before a
+// change is made to it
+type Precondition func(project *models.Project) error
+
+// IfVersion makes a change conditional on the project still being at version,
+// in the manner of an HTTP If-Match header. Otherwise ErrVersionConflict is returned.
+func IfVersion(version int64) Precondition {
+	return func(project *models.Project) error {
+		if project.Version != version {
+			return fmt.Errorf("%w: project %s is at version %d, expected %d", ErrVersionConflict, project.ID, project.Version, version)
+		}
+		return nil
+	}
+}
+
+// checkPreconditions returns the first precondition that fails
+func checkPreconditions(project *models.Project, preconditions []Precondition) error {
+	for _, precondition := range preconditions {
+		if err := precondition(project); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Service manages project sessions and voting logic
 type Service struct {
-	store  storage.ProjectStore
-	voting *VotingService
-	mu     sync.RWMutex
+	store      storage.ProjectStore
+	votes      storage.VoteStore
+	voting     *VotingService
+	onEscalate EscalationHandler
+	onResolve  DecisionHandler
+	onComplete CompletionHandler
+	events     *EventBus
+	projects   storage.LockStripes // serializes operations on each project
+	mu         sync.RWMutex        // guards the handlers
 }
 
 // NewService creates a new project service
-func NewService(store storage.ProjectStore, voting *VotingService) *Service {
+func NewService(store storage.ProjectStore, votes storage.VoteStore, voting *VotingService) *Service {
 	return &Service{
 		store:  store,
+		votes:  votes,
 		voting: voting,
+		events: NewEventBus(),
 	}
 }
 
-// CreateProject creates a new project session
-func (s *Service) CreateProject(id, name string, k, maxTurns int) (*models.Project, error) {
+// Subscribe streams events for a project until the returned cancel function
+// is called. Only events raised through this Service are delivered.
+func (s *Service) Subscribe(projectID string) (<-chan Event, func()) {
+	return s.events.Subscribe(projectID)
+}
+
+// decisionResolved notifies subscribers and the decision handler that a
+// decision stopped accepting votes; the decision must already be saved
+func (s *Service) decisionResolved(project *models.Project, decision *models.Decision) {
+	s.events.Publish(decisionEvent(EventDecisionCompleted, decision))
+
+	s.mu.RLock()
+	handler := s.onResolve
+	s.mu.RUnlock()
+	if handler != nil {
+		handler(project, decision)
+	}
+}
+
+// decisionEscalated notifies the escalation handler that a decision was
+// escalated; the decision must already be saved
+func (s *Service) decisionEscalated(project *models.Project, decision *models.Decision) {
+	s.mu.RLock()
+	handler := s.onEscalate
+	s.mu.RUnlock()
+	if handler != nil {
+		handler(project, decision)
+	}
+}
+
+// projectFinished notifies subscribers and the completion handler that a
+// project was completed or cancelled; the project must already be saved
+func (s *Service) projectFinished(project *models.Project) {
+	s.events.Publish(projectEvent(EventProjectCompleted, project))
+
+	s.mu.RLock()
+	handler := s.onComplete
+	s.mu.RUnlock()
+	if handler != nil {
+		handler(project)
+	}
+}
+
+// SetEscalationHandler registers a handler for escalated decisions. The handler
+// runs synchronously once the decision is saved and must not call back into the Service.
+func (s *Service) SetEscalationHandler(handler EscalationHandler) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	s.onEscalate = handler
+}
+
+// SetDecisionHandler registers a handler for decisions that are resolved by a
+// vote or a deadline. The handler runs synchronously once the decision is saved
+// and must not call back into the Service.
+func (s *Service) SetDecisionHandler(handler DecisionHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.onResolve = handler
+}
+
+// SetCompletionHandler registers a handler for finished projects. The handler
+// runs synchronously once the project is saved and must not call back into the Service.
+func (s *Service) SetCompletionHandler(handler CompletionHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.onComplete = handler
+}
+
+// CreateProject creates a new project session with default settings
+func (s *Service) CreateProject(id, name string, k, maxTurns int) (*models.Project, error) {
+	return s.CreateProjectWithSettings(id, name, k, maxTurns, models.ProjectSettings{})
+}
+
+// lockProject serializes read-modify-write cycles on a project with other
+// processes sharing the store. Stores that cannot lock rely on s.projects alone.
+func (s *Service) lockProject(id string) (func(), error) {
+	locker, ok := s.store.(storage.ProjectLocker)
+	if !ok {
+		return func() {}, nil
+	}
+
+	unlock, err := locker.LockProject(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock project %s: %w", id, err)
+	}
+	return unlock, nil
+}
+
+// saveProject saves a project only if nobody else saved it since it was
+// loaded, recording events in its history. Events are stamped with the
+// project's UpdatedAt, so replaying them restores it.
+func (s *Service) saveProject(project *models.Project, events ...models.HistoryEvent) error {
+	for i := range events {
+		events[i].Time = project.UpdatedAt
+	}
+	return s.store.SaveProjectIfVersion(project, project.Version, events...)
+}
+
+// decisionChanged returns a history event carrying a snapshot of a decision
+// and of the project's metrics
+func decisionChanged(eventType models.HistoryEventType, project *models.Project, decision *models.Decision) models.HistoryEvent {
+	metrics := project.Metrics
+	return models.HistoryEvent{
+		Type:       eventType,
+		DecisionID: decision.ID,
+		Decision:   decision,
+		Metrics:    &metrics,
+	}
+}
+
+// CreateProjectWithSettings creates a new project session with the given voting policies
+func (s *Service) CreateProjectWithSettings(id, name string, k, maxTurns int, settings models.ProjectSettings) (*models.Project, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSettings, err)
+	}
+	if _, err := voting.NewNormalizerChain(settings.Normalizers); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSettings, err)
+	}
+	if _, err := voting.NewRedFlagFilter(settings.RedFlags); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSettings, err)
+	}
+
+	lock := s.projects.For(id)
+	lock.Lock()
+	defer lock.Unlock()
+
+	unlock, err := s.lockProject(id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if _, err := s.store.GetProject(id); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrProjectExists, id)
+	} else if !errors.Is(err, storage.ErrProjectNotFound) {
+		return nil, fmt.Errorf("failed to check for existing project: %w", err)
+	}
+
 	project := models.NewProject(id, name, k, maxTurns)
+	project.Settings = settings
 
-	if err := s.store.SaveProject(project); err != nil {
+	created := models.HistoryEvent{Type: models.HistoryProjectCreated, Project: project}
+	if err := s.saveProject(project, created); err != nil {
 		return nil, fmt.Errorf("failed to save project: %w", err)
 	}
 
@@ -50,28 +253,88 @@ func (s *Service) CreateProject(id, name string, k, maxTurns int) (*models.Proje
 
 // GetProject retrieves a project by ID
 func (s *Service) GetProject(id string) (*models.Project, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	lock := s.projects.For(id)
+	lock.RLock()
+	defer lock.RUnlock()
 
 	return s.store.GetProject(id)
 }
 
-// ListProjects returns all projects
+// ListProjects returns all projects. If some project files are corrupt, the
+// projects that loaded are returned with an error wrapping ErrCorruptProject.
 func (s *Service) ListProjects() ([]*models.Project, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	return s.store.ListProjects()
 }
 
-// StartDecision starts a new voting decision for a project
-func (s *Service) StartDecision(projectID, decisionID, description string, options []string) (*models.Decision, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// DecisionConfig holds optional parameters for starting a decision
+type DecisionConfig struct {
+	// Open lets any submitted answer become a candidate; options seed the candidate set
+	Open bool
+	// Deadline closes voting at an absolute time
+	Deadline time.Time
+	// Timeout closes voting after a duration; it defaults to the project's decision timeout
+	Timeout time.Duration
+}
+
+// StartDecision starts a new voting decision for a project. An empty
+// decisionID generates a unique turn-based ID.
+func (s *Service) StartDecision(projectID, decisionID, description string, options []string, preconditions ...Precondition) (*models.Decision, error) {
+	_, decision, err := s.StartDecisionWithConfig(projectID, decisionID, description, options, DecisionConfig{}, preconditions...)
+	return decision, err
+}
+
+// StartDecisionWithConfig starts a new voting decision for a project using the
+// given configuration. It returns the project as saved with the decision.
+func (s *Service) StartDecisionWithConfig(projectID, decisionID, description string, options []string, config DecisionConfig, preconditions ...Precondition) (*models.Project, *models.Decision, error) {
+	var saved *models.Project
+	var decision *models.Decision
+	err := s.updateProject(projectID, preconditions, func(project *models.Project) error {
+		var err error
+		decision, err = s.startDecision(project, decisionID, description, options, config)
+		saved = project
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return saved, decision, nil
+}
+
+// updateProject loads a project under its locks, checks the preconditions
+// against it and hands it to change, so the checks and the change see the
+// same version
+func (s *Service) updateProject(projectID string, preconditions []Precondition, change func(project *models.Project) error) error {
+	lock := s.projects.For(projectID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	unlock, err := s.lockProject(projectID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	project, err := s.store.GetProject(projectID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get project: %w", err)
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	if err := checkPreconditions(project, preconditions); err != nil {
+		return err
+	}
+
+	return change(project)
+}
+
+// startDecision starts a decision on a loaded project and saves it; the
+// caller must hold the project's locks
+func (s *Service) startDecision(project *models.Project, decisionID, description string, options []string, config DecisionConfig) (*models.Decision, error) {
+	if !config.Open && len(options) == 0 {
+		return nil, fmt.Errorf("%w: at least one option is required", ErrInvalidDecision)
+	}
+
+	if project.State == models.ProjectStatePaused {
+		return nil, ErrProjectPaused
 	}
 
 	if !project.CanAcceptVotes() {
@@ -80,74 +343,537 @@ func (s *Service) StartDecision(projectID, decisionID, description string, optio
 
 	// Check if there's already an active decision
 	if project.GetCurrentDecision() != nil {
-		return nil, errors.New("project already has an active decision")
+		return nil, ErrDecisionActive
+	}
+
+	turn := project.TurnsUsed() + 1
+	if decisionID == "" {
+		decisionID = nextDecisionID(project, turn)
+	} else if project.FindDecision(decisionID) != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateDecision, decisionID)
+	}
+
+	var decision *models.Decision
+	if config.Open {
+		// Seed candidates are stored in canonical form, like discovered ones
+		normalizer, err := voting.NewNormalizerChain(project.Settings.Normalizers)
+		if err != nil {
+			return nil, fmt.Errorf("invalid normalizer settings: %w", err)
+		}
+		candidates := make([]string, 0, len(options))
+		for _, option := range options {
+			candidates = append(candidates, normalizer.Normalize(option))
+		}
+		decision = models.NewOpenDecision(decisionID, project.ID, description, turn, candidates)
+	} else {
+		decision = models.NewDecision(decisionID, project.ID, description, turn, options)
+	}
+	decision.TieBreak = project.TieBreakRule()
+
+	switch {
+	case !config.Deadline.IsZero():
+		if !config.Deadline.After(decision.VotingStarted) {
+			return nil, fmt.Errorf("%w: deadline must be in the future", ErrInvalidDecision)
+		}
+		decision.SetDeadline(config.Deadline)
+	case config.Timeout > 0:
+		decision.SetDeadline(decision.VotingStarted.Add(config.Timeout))
+	case project.Settings.DecisionTimeout > 0:
+		decision.SetDeadline(decision.VotingStarted.Add(project.Settings.DecisionTimeout))
 	}
 
-	decision := models.NewDecision(decisionID, projectID, description, project.CurrentTurn+1, options)
 	project.Decisions = append(project.Decisions, *decision)
 	project.CurrentTurn = decision.TurnNumber
 	project.UpdatedAt = time.Now()
 
-	if err := s.store.SaveProject(project); err != nil {
+	started := models.HistoryEvent{Type: models.HistoryDecisionStarted, DecisionID: decision.ID, Decision: decision}
+	if err := s.saveProject(project, started); err != nil {
 		return nil, fmt.Errorf("failed to save project: %w", err)
 	}
 
+	s.events.Publish(decisionEvent(EventDecisionStarted, decision))
+
 	return decision, nil
 }
 
-// CastVote casts a vote for a decision
-func (s *Service) CastVote(projectID, decisionID, agentID, option string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// nextDecisionID returns a turn-based decision ID not yet used in the project
+func nextDecisionID(project *models.Project, turn int) string {
+	id := fmt.Sprintf("decision_%d", turn)
+	for n := 2; project.FindDecision(id) != nil; n++ {
+		id = fmt.Sprintf("decision_%d_%d", turn, n)
+	}
+	return id
+}
+
+// CastVote casts a vote for a decision. Answers caught by the project's
+// red-flag rules are recorded but not counted, and ErrVoteFlagged is returned.
+// Other answers are canonicalized with the project's normalizer chain before
+// they are matched against the options.
+//
+// The vote is applied under the project's lock, so votes from other processes
+// sharing the store are serialized. Stores that cannot lock fall back to
+// retrying the vote against the new version if another writer saved the
+// project in the meantime. The project is returned as this vote saved it, so
+// its Version is the one the vote wrote.
+func (s *Service) CastVote(projectID, decisionID, agentID, answer string, preconditions ...Precondition) (*models.Project, error) {
+	lock := s.projects.For(projectID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	var saved *models.Project
+	err := s.voteCycle(projectID, preconditions, func() error {
+		project, decision, err := s.votingDecision(projectID, decisionID, preconditions)
+		if err != nil {
+			return err
+		}
+
+		if err := s.castVote(project, decision, agentID, answer, ""); err != nil {
+			return err
+		}
+		saved = project
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// voteCycle runs a vote's load-change-save cycle under the project's lock.
+// Stores that cannot lock across processes retry the cycle on version
+// conflicts instead.
+func (s *Service) voteCycle(projectID string, preconditions []Precondition, cycle func() error) error {
+	if _, ok := s.store.(storage.ProjectLocker); !ok {
+		return retryOnConflict(cycle, preconditions)
+	}
+
+	unlock, err := s.lockProject(projectID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return cycle()
+}
 
+// retryOnConflict runs a load-change-save cycle until it does not hit a version
+// conflict. Cycles with preconditions are not retried, because the caller
+// asked for the version they loaded.
+func retryOnConflict(cycle func() error, preconditions []Precondition) error {
+	for attempt := 1; ; attempt++ {
+		err := cycle()
+		if !errors.Is(err, ErrVersionConflict) || len(preconditions) > 0 || attempt == maxVoteAttempts {
+			return err
+		}
+		time.Sleep(conflictBackoff(attempt))
+	}
+}
+
+// conflictBackoff returns a random wait before retrying after the given number
+// of conflicts. The bound doubles with each attempt up to maxConflictBackoff,
+// so writers that collided spread out instead of colliding again.
+func conflictBackoff(attempt int) time.Duration {
+	bound := time.Millisecond << min(attempt-1, 6)
+	return time.Duration(rand.Int63n(int64(min(bound, maxConflictBackoff))))
+}
+
+// votingDecision loads a project and one of its decisions, checking that the
+// decision can take a vote now. An expired deadline is applied first.
+func (s *Service) votingDecision(projectID, decisionID string, preconditions []Precondition) (*models.Project, *models.Decision, error) {
 	project, err := s.store.GetProject(projectID)
 	if err != nil {
-		return fmt.Errorf("failed to get project: %w", err)
+		return nil, nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	if err := checkPreconditions(project, preconditions); err != nil {
+		return nil, nil, err
+	}
+
+	if project.State == models.ProjectStatePaused {
+		return nil, nil, ErrProjectPaused
 	}
 
 	if !project.CanAcceptVotes() {
-		return ErrProjectNotActive
+		return nil, nil, ErrProjectNotActive
 	}
 
-	decision := project.GetCurrentDecision()
-	if decision == nil || decision.ID != decisionID {
-		return ErrDecisionNotFound
+	if _, err := s.checkDeadline(project, time.Now()); err != nil {
+		return nil, nil, err
+	}
+
+	decision := project.FindDecision(decisionID)
+	if decision == nil {
+		return nil, nil, ErrDecisionNotFound
 	}
 
 	if decision.State != models.DecisionStateVoting {
-		return ErrVotingClosed
+		return nil, nil, ErrVotingClosed
+	}
+
+	return project, decision, nil
+}
+
+// castVote is the pipeline every vote goes through: red flags, normalization,
+// the re-vote policy, the K-ahead check and vote budget, then saving the
+// project and vote record and notifying subscribers. Votes chosen by a
+// strategy are tagged with its name.
+func (s *Service) castVote(project *models.Project, decision *models.Decision, agentID, answer, strategy string) error {
+	filter, err := voting.NewRedFlagFilter(project.Settings.RedFlags)
+	if err != nil {
+		return fmt.Errorf("invalid red-flag settings: %w", err)
+	}
+	if reason := filter.Check(answer); reason != "" {
+		return s.recordFlaggedVote(project, decision, agentID, answer, strategy, reason)
 	}
 
+	normalizer, err := voting.NewNormalizerChain(project.Settings.Normalizers)
+	if err != nil {
+		return fmt.Errorf("invalid normalizer settings: %w", err)
+	}
+	option := normalizer.Resolve(decision, answer)
+
 	// Cast the vote
-	if err := s.voting.CastVote(decision, agentID, option); err != nil {
+	if err := s.voting.CastVote(decision, agentID, option, project.RevotePolicy()); err != nil {
 		return err
 	}
+	vote := models.NewVote(decision.ID, project.ID, agentID, option)
+	vote.RawOption = answer
+	vote.Strategy = strategy
 
-	// Check for winner
+	// Check for winner, then whether the vote budget has run out
+	escalated := false
 	if winner := decision.CheckWinner(project.K); winner != nil {
+		s.resolveDecision(project, decision, winner, models.ResolutionKAhead, "")
+	} else if budget := project.Settings.MaxVotesPerDecision; budget > 0 && decision.VoteSequence >= budget {
+		reason := fmt.Sprintf("vote budget of %d exhausted", budget)
+		escalated = s.applyFallback(project, decision, project.BudgetFallback(), reason)
+	}
+
+	project.UpdatedAt = time.Now()
+
+	events := []models.HistoryEvent{{
+		Type:       models.HistoryVoteCast,
+		DecisionID: decision.ID,
+		AgentID:    agentID,
+		Option:     option,
+	}}
+	if decision.State != models.DecisionStateVoting {
+		events = append(events, decisionChanged(models.HistoryDecisionResolved, project, decision))
+	}
+	if err := s.saveProject(project, events...); err != nil {
+		return fmt.Errorf("failed to save project: %w", err)
+	}
+
+	if err := s.votes.SaveVote(vote); err != nil {
+		return fmt.Errorf("failed to record vote: %w", err)
+	}
+
+	event := decisionEvent(EventVoteCast, decision)
+	event.AgentID = agentID
+	event.Option = option
+	s.events.Publish(event)
+
+	if escalated {
+		s.decisionEscalated(project, decision)
+	}
+	if decision.State != models.DecisionStateVoting {
+		s.decisionResolved(project, decision)
+	}
+
+	return nil
+}
+
+// resolveDecision closes voting on a decision and records how it was resolved.
+// Decisions with a winner count towards the project's consensus metrics.
+func (s *Service) resolveDecision(project *models.Project, decision *models.Decision, winner *string, resolution models.Resolution, reason string) {
+	now := time.Now()
+	decision.Winner = winner
+	decision.CompletedAt = &now
+	decision.Resolution = resolution
+	decision.ResolutionReason = reason
+
+	switch {
+	case winner != nil:
 		decision.State = models.DecisionStateCompleted
-		decision.Winner = winner
-		now := time.Now()
-		decision.CompletedAt = &now
-
-		// Update project metrics
-		project.Metrics.TotalDecisions++
-		project.Metrics.TotalVotes += s.getTotalVotes(decision)
-		if project.Metrics.TotalDecisions > 0 {
-			// Calculate average consensus time
-			totalTime := time.Duration(0)
-			for _, d := range project.Decisions {
-				if d.CompletedAt != nil {
-					totalTime += d.CompletedAt.Sub(d.VotingStarted)
-				}
+	case resolution == models.ResolutionEscalated:
+		decision.State = models.DecisionStateEscalated
+	default:
+		decision.State = models.DecisionStateCancelled
+	}
+
+	if winner == nil {
+		return
+	}
+
+	// Update project metrics
+	project.Metrics.TotalDecisions++
+	project.Metrics.TotalVotes += s.getTotalVotes(decision)
+	if project.Metrics.TotalDecisions > 0 {
+		// Calculate average consensus time
+		totalTime := time.Duration(0)
+		for _, d := range project.Decisions {
+			if d.State == models.DecisionStateCompleted && d.CompletedAt != nil {
+				totalTime += d.ConsensusTime()
 			}
-			project.Metrics.AverageConsensusTime = totalTime / time.Duration(project.Metrics.TotalDecisions)
 		}
+		project.Metrics.AverageConsensusTime = totalTime / time.Duration(project.Metrics.TotalDecisions)
 	}
+}
+
+// applyFallback resolves a decision that did not reach K-ahead consensus and
+// reports whether it was escalated
+func (s *Service) applyFallback(project *models.Project, decision *models.Decision, action models.FallbackAction, reason string) bool {
+	switch action {
+	case models.FallbackEscalate:
+		s.resolveDecision(project, decision, nil, models.ResolutionEscalated, reason)
+		return true
+	case models.FallbackCancel:
+		s.resolveDecision(project, decision, nil, models.ResolutionNoConsensus, reason)
+	default:
+		if leader, votes := decision.Leader(); votes > 0 {
+			s.resolveDecision(project, decision, &leader, models.ResolutionPlurality, reason)
+		} else {
+			s.resolveDecision(project, decision, nil, models.ResolutionNoConsensus, reason)
+		}
+	}
+	return false
+}
 
+// recordFlaggedVote records a red-flagged vote without counting it
+func (s *Service) recordFlaggedVote(project *models.Project, decision *models.Decision, agentID, answer, strategy, reason string) error {
+	decision.FlaggedVotes++
 	project.UpdatedAt = time.Now()
 
-	if err := s.store.SaveProject(project); err != nil {
+	flagged := models.HistoryEvent{
+		Type:       models.HistoryVoteCast,
+		DecisionID: decision.ID,
+		AgentID:    agentID,
+		Flagged:    true,
+	}
+	if err := s.saveProject(project, flagged); err != nil {
+		return fmt.Errorf("failed to save project: %w", err)
+	}
+
+	vote := models.NewVote(decision.ID, project.ID, agentID, "")
+	vote.RawOption = answer
+	vote.Strategy = strategy
+	vote.Flagged = true
+	vote.FlagReason = reason
+	if err := s.votes.SaveVote(vote); err != nil {
+		return fmt.Errorf("failed to record vote: %w", err)
+	}
+
+	return fmt.Errorf("%w: %s", ErrVoteFlagged, reason)
+}
+
+// checkDeadline expires the project's current decision if its deadline has
+// passed, applying the project's timeout outcome and saving the project. It
+// returns the decision that expired or was extended, if any.
+func (s *Service) checkDeadline(project *models.Project, now time.Time) (*models.Decision, error) {
+	decision := project.GetCurrentDecision()
+	if decision == nil || !decision.IsExpired(now) {
+		return nil, nil
+	}
+
+	escalated := false
+	eventType := models.HistoryDecisionResolved
+	if action := project.TimeoutOutcome(); action == models.FallbackExtend && decision.Timeout > 0 {
+		deadline := now.Add(decision.Timeout)
+		decision.Deadline = &deadline
+		decision.Extensions++
+		eventType = models.HistoryDecisionExtended
+	} else {
+		reason := fmt.Sprintf("deadline %s passed", decision.Deadline.Format(time.RFC3339))
+		decision.ExpiredAt = &now
+		escalated = s.applyFallback(project, decision, action, reason)
+	}
+	project.UpdatedAt = now
+
+	if err := s.saveProject(project, decisionChanged(eventType, project, decision)); err != nil {
+		return nil, fmt.Errorf("failed to save project: %w", err)
+	}
+
+	if escalated {
+		s.decisionEscalated(project, decision)
+	}
+	if decision.State != models.DecisionStateVoting {
+		s.decisionResolved(project, decision)
+	}
+
+	return decision, nil
+}
+
+// SweepExpiredDecisions applies the timeout outcome to every active decision
+// whose deadline has passed and returns what happened to each. Corrupt project
+// files do not stop the sweep; they are reported in the returned error.
+func (s *Service) SweepExpiredDecisions() ([]SweepResult, error) {
+	projects, listErr := s.store.ListProjects()
+	if listErr != nil && !errors.Is(listErr, ErrCorruptProject) {
+		return nil, fmt.Errorf("failed to list projects: %w", listErr)
+	}
+
+	results := []SweepResult{}
+	now := time.Now()
+	for _, listed := range projects {
+		if !listed.CanAcceptVotes() {
+			continue
+		}
+
+		project, decision, err := s.sweepProject(listed.ID, now)
+		if err != nil {
+			return results, err
+		}
+		if decision == nil {
+			continue
+		}
+
+		results = append(results, SweepResult{
+			ProjectID:  project.ID,
+			DecisionID: decision.ID,
+			State:      decision.State,
+			Resolution: decision.Resolution,
+			Extended:   decision.State == models.DecisionStateVoting,
+		})
+	}
+
+	return results, listErr
+}
+
+// sweepProject applies an expired deadline on one project under its lock. The
+// project is reloaded because another process may have changed it since it
+// was listed.
+func (s *Service) sweepProject(projectID string, now time.Time) (*models.Project, *models.Decision, error) {
+	lock := s.projects.For(projectID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	unlock, err := s.lockProject(projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+
+	project, err := s.store.GetProject(projectID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if !project.CanAcceptVotes() {
+		return project, nil, nil
+	}
+
+	decision, err := s.checkDeadline(project, now)
+	return project, decision, err
+}
+
+// SweepResult describes a decision handled by SweepExpiredDecisions
+type SweepResult struct {
+	ProjectID  string               `json:"project_id"`
+	DecisionID string               `json:"decision_id"`
+	State      models.DecisionState `json:"state"`
+	Resolution models.Resolution    `json:"resolution,omitempty"`
+	Extended   bool                 `json:"extended"`
+}
+
+// GetVotes returns the recorded votes for a project, in the order they were cast
+func (s *Service) GetVotes(projectID string) ([]*models.Vote, error) {
+	lock := s.projects.For(projectID)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return s.votes.GetVotesByProject(projectID)
+}
+
+// PauseProject pauses an active project. Paused projects reject votes and
+// their decision deadlines and consensus clocks stop until they are resumed.
+func (s *Service) PauseProject(projectID string, preconditions ...Precondition) error {
+	lock := s.projects.For(projectID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	unlock, err := s.lockProject(projectID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	project, err := s.store.GetProject(projectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	if err := checkPreconditions(project, preconditions); err != nil {
+		return err
+	}
+
+	if project.State == models.ProjectStatePaused {
+		return ErrProjectPaused
+	}
+
+	if !project.CanAcceptVotes() {
+		return ErrProjectNotActive
+	}
+
+	// Settle any deadline that passed before the pause
+	if _, err := s.checkDeadline(project, time.Now()); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	project.State = models.ProjectStatePaused
+	project.PausedAt = &now
+	project.UpdatedAt = now
+
+	if err := s.saveProject(project, models.HistoryEvent{Type: models.HistoryProjectPaused}); err != nil {
+		return fmt.Errorf("failed to save project: %w", err)
+	}
+
+	return nil
+}
+
+// ResumeProject resumes a paused project, pushing the current decision's
+// deadline back by the time spent paused
+func (s *Service) ResumeProject(projectID string, preconditions ...Precondition) error {
+	lock := s.projects.For(projectID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	unlock, err := s.lockProject(projectID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	project, err := s.store.GetProject(projectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	if err := checkPreconditions(project, preconditions); err != nil {
+		return err
+	}
+
+	if project.State != models.ProjectStatePaused {
+		return ErrProjectNotPaused
+	}
+
+	now := time.Now()
+	resumed := models.HistoryEvent{Type: models.HistoryProjectResumed}
+	if decision := project.GetCurrentDecision(); decision != nil && project.PausedAt != nil {
+		paused := now.Sub(*project.PausedAt)
+		decision.PausedDuration += paused
+		if decision.Deadline != nil {
+			deadline := decision.Deadline.Add(paused)
+			decision.Deadline = &deadline
+		}
+		resumed.DecisionID = decision.ID
+		resumed.Decision = decision
+	}
+
+	project.State = models.ProjectStateActive
+	project.PausedAt = nil
+	project.UpdatedAt = now
+
+	if err := s.saveProject(project, resumed); err != nil {
 		return fmt.Errorf("failed to save project: %w", err)
 	}
 
@@ -155,46 +881,187 @@ func (s *Service) CastVote(projectID, decisionID, agentID, option string) error
 }
 
 // EndProject ends a project session
-func (s *Service) EndProject(projectID string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *Service) EndProject(projectID string, preconditions ...Precondition) error {
+	return s.updateProject(projectID, preconditions, func(project *models.Project) error {
+		if project.IsComplete() {
+			return errors.New("project is already complete")
+		}
+		return s.completeProject(project)
+	})
+}
+
+// completeProject marks a loaded project completed, closing any active
+// decision, and saves it; the caller must hold the project's locks
+func (s *Service) completeProject(project *models.Project) error {
+	project.State = models.ProjectStateCompleted
+	now := time.Now()
+	project.CompletedAt = &now
+	project.PausedAt = nil
+	project.UpdatedAt = now
+
+	// Close any active decision
+	current := project.GetCurrentDecision()
+	if current != nil {
+		s.resolveDecision(project, current, nil, models.ResolutionCancelled, "project ended")
+	}
+
+	if err := s.saveProject(project, endedEvents(project, current)...); err != nil {
+		return fmt.Errorf("failed to save project: %w", err)
+	}
+
+	if current != nil {
+		s.decisionResolved(project, current)
+	}
+	s.projectFinished(project)
+
+	return nil
+}
+
+// CancelProject aborts a project. Unlike EndProject the project is marked
+// cancelled rather than completed, and the reason is recorded on the project
+// and on any decision that was still open.
+func (s *Service) CancelProject(projectID, reason string, preconditions ...Precondition) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrReasonRequired
+	}
+
+	lock := s.projects.For(projectID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	unlock, err := s.lockProject(projectID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	project, err := s.store.GetProject(projectID)
 	if err != nil {
 		return fmt.Errorf("failed to get project: %w", err)
 	}
 
+	if err := checkPreconditions(project, preconditions); err != nil {
+		return err
+	}
+
 	if project.IsComplete() {
 		return errors.New("project is already complete")
 	}
 
-	project.State = models.ProjectStateCompleted
+	current := project.GetCurrentDecision()
+	if current != nil {
+		s.resolveDecision(project, current, nil, models.ResolutionCancelled, reason)
+	}
+
 	now := time.Now()
+	project.State = models.ProjectStateCancelled
+	project.CancelReason = reason
 	project.CompletedAt = &now
+	project.PausedAt = nil
 	project.UpdatedAt = now
 
-	// Close any active decision
-	if decision := project.GetCurrentDecision(); decision != nil {
-		decision.State = models.DecisionStateCancelled
+	if err := s.saveProject(project, endedEvents(project, current)...); err != nil {
+		return fmt.Errorf("failed to save project: %w", err)
+	}
+
+	if current != nil {
+		s.decisionResolved(project, current)
+	}
+	s.projectFinished(project)
+
+	return nil
+}
+
+// endedEvents returns the history events for ending a project, closing the
+// decision that was still open, if any
+func endedEvents(project *models.Project, current *models.Decision) []models.HistoryEvent {
+	var events []models.HistoryEvent
+	if current != nil {
+		events = append(events, decisionChanged(models.HistoryDecisionResolved, project, current))
+	}
+	return append(events, models.HistoryEvent{
+		Type:   models.HistoryProjectEnded,
+		State:  project.State,
+		Reason: project.CancelReason,
+	})
+}
+
+// CancelDecision cancels a decision that is still open for voting or was
+// escalated, leaving the project free to start another one in its turn
+func (s *Service) CancelDecision(projectID, decisionID, reason string, preconditions ...Precondition) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrReasonRequired
+	}
+
+	lock := s.projects.For(projectID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	unlock, err := s.lockProject(projectID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	project, err := s.store.GetProject(projectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	if err := checkPreconditions(project, preconditions); err != nil {
+		return err
+	}
+
+	if project.IsComplete() {
+		return ErrProjectNotActive
 	}
 
-	if err := s.store.SaveProject(project); err != nil {
+	decision := project.FindDecision(decisionID)
+	if decision == nil {
+		return ErrDecisionNotFound
+	}
+	if decision.State != models.DecisionStateVoting && decision.State != models.DecisionStateEscalated {
+		return ErrVotingClosed
+	}
+
+	s.resolveDecision(project, decision, nil, models.ResolutionCancelled, reason)
+	project.UpdatedAt = time.Now()
+
+	if err := s.saveProject(project, decisionChanged(models.HistoryDecisionResolved, project, decision)); err != nil {
 		return fmt.Errorf("failed to save project: %w", err)
 	}
 
+	s.decisionResolved(project, decision)
+
 	return nil
 }
 
-// GetProjectStatus returns the current status of a project
+// GetProjectStatus returns the current status of a project, expiring the
+// current decision first if its deadline has passed
 func (s *Service) GetProjectStatus(projectID string) (*ProjectStatus, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	lock := s.projects.For(projectID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	unlock, err := s.lockProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	project, err := s.store.GetProject(projectID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get project: %w", err)
 	}
 
+	if project.CanAcceptVotes() {
+		if _, err := s.checkDeadline(project, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
 	status := &ProjectStatus{
 		Project:  project,
 		IsActive: project.CanAcceptVotes(),
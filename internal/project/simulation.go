@@ -0,0 +1,105 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bneil/voter/internal/models"
+)
+
+// VoteChooser picks the answer an agent votes for using the named strategy.
+// It sees the decision as it stands before the vote and must not call back
+// into the Service.
+type VoteChooser func(strategy string, project *models.Project, decision *models.Decision, agentID string) string
+
+// SimulationResult describes the votes cast by SimulateVoting
+type SimulationResult struct {
+	VotesCast int              `json:"votes_cast"`
+	Flagged   int              `json:"flagged"`
+	Decision  *models.Decision `json:"decision"`
+	// Resolved is set when the simulation stopped because the decision
+	// stopped accepting votes
+	Resolved bool `json:"resolved"`
+}
+
+// CastStrategicVote casts a vote for the answer chosen by a strategy. The vote
+// goes through the same pipeline as CastVote and is recorded with the strategy
+// name. Like CastVote it runs under the project's lock, or on stores that
+// cannot lock is retried if another writer saves the project first, choosing
+// again against the new version. It returns the answer that was chosen.
+func (s *Service) CastStrategicVote(projectID, decisionID, agentID, strategy string, choose VoteChooser, preconditions ...Precondition) (string, error) {
+	lock := s.projects.For(projectID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	var answer string
+	err := s.voteCycle(projectID, preconditions, func() error {
+		project, decision, err := s.votingDecision(projectID, decisionID, preconditions)
+		if err != nil {
+			return err
+		}
+
+		answer = choose(strategy, project, decision, agentID)
+		return s.castVote(project, decision, agentID, answer, strategy)
+	})
+	return answer, err
+}
+
+// SimulateVoting casts votes for up to agentCount simulated agents, assigning
+// strategies in turn. Agents are numbered after any that already voted. Every
+// vote is saved and checked for K-ahead consensus, and the simulation stops as
+// soon as the decision stops accepting votes. Red-flagged votes are counted in
+// the result and do not stop the simulation. The project is locked for the
+// whole simulation.
+func (s *Service) SimulateVoting(projectID, decisionID string, agentCount int, strategies []string, choose VoteChooser, preconditions ...Precondition) (*SimulationResult, error) {
+	if agentCount < 1 {
+		return nil, fmt.Errorf("%w: agent count must be at least 1", ErrInvalidVote)
+	}
+	if len(strategies) == 0 {
+		return nil, fmt.Errorf("%w: no strategies to simulate", ErrInvalidVote)
+	}
+
+	lock := s.projects.For(projectID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	unlock, err := s.lockProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	project, decision, err := s.votingDecision(projectID, decisionID, preconditions)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SimulationResult{Decision: decision}
+	next := 0
+	for i := 0; i < agentCount; i++ {
+		for decision.HasVoted(fmt.Sprintf("agent_%d", next)) {
+			next++
+		}
+		agentID := fmt.Sprintf("agent_%d", next)
+		next++
+		strategy := strategies[i%len(strategies)]
+
+		answer := choose(strategy, project, decision, agentID)
+		err := s.castVote(project, decision, agentID, answer, strategy)
+		switch {
+		case errors.Is(err, ErrVoteFlagged):
+			result.Flagged++
+		case err != nil:
+			return result, fmt.Errorf("failed to cast vote for agent %s: %w", agentID, err)
+		default:
+			result.VotesCast++
+		}
+
+		if decision.State != models.DecisionStateVoting {
+			result.Resolved = true
+			break
+		}
+	}
+
+	return result, nil
+}
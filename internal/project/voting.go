@@ -2,8 +2,6 @@ package project
 
 import (
 	"errors"
-	"sync"
-	"time"
 
 	"github.com/bneil/voter/internal/models"
 )
@@ -12,26 +10,25 @@ var (
 	ErrInvalidVote = errors.New("invalid vote")
 )
 
-// VotingService handles voting operations with atomicity
-type VotingService struct {
-	mu sync.RWMutex
-}
+// VotingService applies votes to decisions. It holds no state of its own;
+// callers serialize changes to a decision with the Service's project lock.
+type VotingService struct{}
 
 // NewVotingService creates a new voting service
 func NewVotingService() *VotingService {
 	return &VotingService{}
 }
 
-// CastVote casts a vote for a decision with atomic operations
-func (vs *VotingService) CastVote(decision *models.Decision, agentID, option string) error {
-	vs.mu.Lock()
-	defer vs.mu.Unlock()
-
+// CastVote casts a vote for a decision, enforcing the re-vote policy
+func (vs *VotingService) CastVote(decision *models.Decision, agentID, option string, policy models.RevotePolicy) error {
 	if decision.State != models.DecisionStateVoting {
 		return ErrVotingClosed
 	}
 
-	if !decision.AddVote(option) {
+	if err := decision.AddAgentVote(agentID, option, policy); err != nil {
+		if errors.Is(err, models.ErrDuplicateVote) {
+			return ErrDuplicateVote
+		}
 		return ErrInvalidVote
 	}
 
@@ -40,24 +37,9 @@ func (vs *VotingService) CastVote(decision *models.Decision, agentID, option str
 
 // GetVoteCounts returns the current vote counts for a decision
 func (vs *VotingService) GetVoteCounts(decision *models.Decision) map[string]int {
-	vs.mu.RLock()
-	defer vs.mu.RUnlock()
-
 	counts := make(map[string]int)
 	for option, count := range decision.Votes {
 		counts[option] = count
 	}
 	return counts
 }
-
-// CreateVote creates a vote record
-func (vs *VotingService) CreateVote(id, decisionID, projectID, agentID, option string) *models.Vote {
-	return &models.Vote{
-		ID:         id,
-		DecisionID: decisionID,
-		ProjectID:  projectID,
-		AgentID:    agentID,
-		Option:     option,
-		Timestamp:  time.Now(),
-	}
-}
@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bneil/voter/internal/models"
+)
+
+// SnapshotInterval is how many history events are recorded between snapshots.
+// Replaying a project starts from the latest snapshot before the target, so at
+// most this many events are folded.
+const SnapshotInterval = 100
+
+// appendHistory appends a save's events to the project's history and syncs
+// them. It is called with the project's file stripe and lock held, before
+// the project itself is written: events only count once the project file
+// records their sequence numbers, so a save that fails after the append
+// leaves nothing behind that readers will use.
+func (s *JSONProjectStore) appendHistory(projectID string, events []models.HistoryEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var data []byte
+	for i := range events {
+		line, err := json.Marshal(&events[i])
+		if err != nil {
+			return fmt.Errorf("failed to marshal history event: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	file, err := os.OpenFile(s.historyPath(projectID), os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer file.Close()
+
+	// An append cut short by a crash leaves a partial last line; start on a
+	// new line so it cannot run into these events
+	if info, err := file.Stat(); err != nil {
+		return fmt.Errorf("failed to stat history: %w", err)
+	} else if info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+			return fmt.Errorf("failed to read history: %w", err)
+		}
+		if last[0] != '\n' {
+			data = append([]byte{'\n'}, data...)
+		}
+	}
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("failed to append history: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync history: %w", err)
+	}
+	return file.Close()
+}
+
+// snapshotIfDue writes a snapshot of a just-saved project whenever its history
+// crosses a multiple of SnapshotInterval. Snapshots only speed up replays, so
+// failing to write one does not fail the save.
+func (s *JSONProjectStore) snapshotIfDue(project *models.Project, previousSeq int64) {
+	if previousSeq/SnapshotInterval == project.HistorySeq/SnapshotInterval {
+		return
+	}
+
+	snapshot, err := json.MarshalIndent(project, "", "  ")
+	if err != nil {
+		return
+	}
+	WriteFileAtomic(s.snapshotPath(project.ID, project.HistorySeq), snapshot, 0644)
+}
+
+// GetHistory returns a project's history events after seq, in order. A
+// project with no recorded history has none. Events left by saves that failed
+// are skipped: unreadable partial lines, and events whose sequence numbers
+// were written again by a later save. Events past the project's own
+// HistorySeq were never committed and are up to the caller to ignore.
+func (s *JSONProjectStore) GetHistory(projectID string, afterSeq int64) ([]models.HistoryEvent, error) {
+	lock := s.files.For(projectID)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	file, err := os.Open(s.historyPath(projectID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []models.HistoryEvent{}, nil
+		}
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer file.Close()
+
+	events := []models.HistoryEvent{}
+	reader := bufio.NewReader(file)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return nil, fmt.Errorf("failed to read history: %w", readErr)
+		}
+
+		var event models.HistoryEvent
+		if len(bytes.TrimSpace(line)) > 0 && json.Unmarshal(line, &event) == nil {
+			// A repeated sequence number means the events from it on were
+			// never committed and have been written again
+			for len(events) > 0 && events[len(events)-1].Seq >= event.Seq {
+				events = events[:len(events)-1]
+			}
+			if event.Seq > afterSeq {
+				events = append(events, event)
+			}
+		}
+
+		if readErr != nil {
+			return events, nil
+		}
+	}
+}
+
+// GetSnapshot returns the latest snapshot of a project taken at or before seq,
+// or nil if there is none
+func (s *JSONProjectStore) GetSnapshot(projectID string, seq int64) (*models.Project, error) {
+	lock := s.files.For(projectID)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	seqs, err := s.snapshotSeqs(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	best := int64(0)
+	for _, snapshotSeq := range seqs {
+		if snapshotSeq <= seq && snapshotSeq > best {
+			best = snapshotSeq
+		}
+	}
+	if best == 0 {
+		return nil, nil
+	}
+
+	path := s.snapshotPath(projectID, best)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var project models.Project
+	if err := json.Unmarshal(data, &project); err != nil {
+		return nil, &CorruptFileError{Path: path, Err: err}
+	}
+	return &project, nil
+}
+
+// deleteHistory removes a project's history and snapshots
+func (s *JSONProjectStore) deleteHistory(projectID string) error {
+	if err := os.Remove(s.historyPath(projectID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete project history: %w", err)
+	}
+
+	seqs, err := s.snapshotSeqs(projectID)
+	if err != nil {
+		return err
+	}
+	for _, seq := range seqs {
+		if err := os.Remove(s.snapshotPath(projectID, seq)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete snapshot: %w", err)
+		}
+	}
+	return nil
+}
+
+// snapshotSeqs returns the history positions of a project's snapshots
+func (s *JSONProjectStore) snapshotSeqs(projectID string) ([]int64, error) {
+	prefix := fmt.Sprintf("snapshot_%s_", projectID)
+	files, err := filepath.Glob(filepath.Join(s.dataDir, prefix+"*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var seqs []int64
+	for _, file := range files {
+		// The prefix also matches projects whose IDs extend this one, e.g.
+		// "a_b" for "a"; only a bare number may follow it
+		rest := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), prefix), ".json")
+		if seq, err := strconv.ParseInt(rest, 10, 64); err == nil && seq > 0 {
+			seqs = append(seqs, seq)
+		}
+	}
+	return seqs, nil
+}
+
+// historyPath returns the event log for a project
+func (s *JSONProjectStore) historyPath(projectID string) string {
+	return filepath.Join(s.dataDir, fmt.Sprintf("history_%s.jsonl", projectID))
+}
+
+// snapshotPath returns the snapshot of a project taken at a history position
+func (s *JSONProjectStore) snapshotPath(projectID string, seq int64) string {
+	return filepath.Join(s.dataDir, fmt.Sprintf("snapshot_%s_%d.json", projectID, seq))
+}
@@ -1,15 +1,92 @@
 package storage
 
-import "github.com/bneil/voter/internal/models"
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/bneil/voter/internal/models"
+)
+
+var (
+	// ErrProjectNotFound is returned by a ProjectStore when no project has the requested ID
+	ErrProjectNotFound = errors.New("project not found")
+	// ErrUnknownBackend is returned by Open for an unsupported store backend
+	ErrUnknownBackend = errors.New("unknown store backend")
+	// ErrCorruptProject is wrapped by errors for project files that cannot be loaded
+	ErrCorruptProject = errors.New("corrupt project file")
+	// ErrVersionConflict is returned when a project changed since it was loaded
+	ErrVersionConflict = errors.New("project version conflict")
+	// ErrLockTimeout is returned when another process holds a project lock for too long
+	ErrLockTimeout = errors.New("timed out waiting for project lock")
+)
+
+// CorruptFileError reports a stored project that cannot be read or parsed,
+// such as one truncated by a crash. The previous version is kept next to it
+// with a .bak suffix.
+type CorruptFileError struct {
+	Path string
+	Err  error
+}
+
+// Error describes the file and why it could not be loaded
+func (e *CorruptFileError) Error() string {
+	return fmt.Sprintf("%s %s: %v", ErrCorruptProject, filepath.Base(e.Path), e.Err)
+}
+
+// Unwrap returns ErrCorruptProject and the underlying error
+func (e *CorruptFileError) Unwrap() []error {
+	return []error{ErrCorruptProject, e.Err}
+}
+
+// Open creates the project and vote stores for a backend in dataDir.
+// "json" is currently the only backend.
+func Open(backend, dataDir string) (ProjectStore, VoteStore, error) {
+	switch backend {
+	case "", "json":
+		projects, err := NewJSONProjectStore(dataDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		votes, err := NewJSONVoteStore(dataDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		return projects, votes, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnknownBackend, backend)
+	}
+}
 
 // ProjectStore defines the interface for project storage operations
 type ProjectStore interface {
-	SaveProject(project *models.Project) error
+	// SaveProjectIfVersion saves a project only if the stored copy is still
+	// at expected, returning ErrVersionConflict otherwise. An expected
+	// version of 0 means the project must not exist yet. Events describing
+	// the change are numbered and added to the project's history as part of
+	// the same save.
+	SaveProjectIfVersion(project *models.Project, expected int64, events ...models.HistoryEvent) error
 	GetProject(id string) (*models.Project, error)
 	ListProjects() ([]*models.Project, error)
 	DeleteProject(id string) error
 }
 
+// ProjectLocker is implemented by stores that can serialize read-modify-write
+// cycles on a project across processes. The returned function releases the lock.
+type ProjectLocker interface {
+	LockProject(id string) (unlock func(), err error)
+}
+
+// HistoryStore is implemented by project stores that keep the history of
+// events recorded by SaveProjectIfVersion
+type HistoryStore interface {
+	// GetHistory returns a project's history events after seq, in order
+	GetHistory(projectID string, afterSeq int64) ([]models.HistoryEvent, error)
+	// GetSnapshot returns the latest snapshot of a project taken at or before
+	// seq, or nil if there is none
+	GetSnapshot(projectID string, seq int64) (*models.Project, error)
+}
+
 // VoteStore defines the interface for vote storage operations
 type VoteStore interface {
 	SaveVote(vote *models.Vote) error
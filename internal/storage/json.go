@@ -2,18 +2,23 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/bneil/voter/internal/models"
 )
 
 // JSONProjectStore implements ProjectStore using JSON file storage
 type JSONProjectStore struct {
-	dataDir string
-	mu      sync.RWMutex
+	dataDir     string
+	files       LockStripes // serializes access to each project's files
+	lockTimeout time.Duration
+	held        map[string]int // project locks held through LockProject
+	mu          sync.RWMutex   // guards lockTimeout and held
 }
 
 // NewJSONProjectStore creates a new JSON-based project store
@@ -23,90 +28,236 @@ func NewJSONProjectStore(dataDir string) (*JSONProjectStore, error) {
 	}
 
 	return &JSONProjectStore{
-		dataDir: dataDir,
+		dataDir:     dataDir,
+		lockTimeout: DefaultLockTimeout,
+		held:        make(map[string]int),
 	}, nil
 }
 
-// SaveProject saves a project to storage
+// SaveProject saves a project to storage without recording history. The
+// version written is one past both the stored version and the project's own,
+// so versions never go back. It is not part of ProjectStore; the Service saves
+// through SaveProjectIfVersion.
 func (s *JSONProjectStore) SaveProject(project *models.Project) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	lock := s.files.For(project.ID)
+	lock.Lock()
+	defer lock.Unlock()
 
-	filename := filepath.Join(s.dataDir, fmt.Sprintf("project_%s.json", project.ID))
+	unlock, err := s.lockFile(project.ID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	filename := s.projectPath(project.ID)
+
+	current, err := storedVersion(filename)
+	if err != nil {
+		return err
+	}
+
+	return writeProject(filename, project, max(current, project.Version)+1)
+}
+
+// SaveProjectIfVersion saves a project only if the stored copy is still at
+// expected. The compare and the write happen under the project's file lock,
+// so the check also holds against other processes. On success the project's
+// Version is advanced and events are appended to its history.
+func (s *JSONProjectStore) SaveProjectIfVersion(project *models.Project, expected int64, events ...models.HistoryEvent) error {
+	lock := s.files.For(project.ID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	unlock, err := s.lockFile(project.ID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	filename := s.projectPath(project.ID)
+
+	current, err := storedVersion(filename)
+	if err != nil {
+		return err
+	}
+	if current != expected {
+		return fmt.Errorf("%w: project %s is at version %d, expected %d", ErrVersionConflict, project.ID, current, expected)
+	}
+
+	// A project saved before history was recorded starts its history with
+	// the project as it was stored, so replays have somewhere to start from
+	upgraded := project.HistorySeq == 0 && expected > 0 && len(events) > 0
+	if upgraded {
+		baseline, err := readProject(filename)
+		if err != nil {
+			return err
+		}
+		created := models.HistoryEvent{Type: models.HistoryProjectCreated, Time: baseline.UpdatedAt, Project: baseline}
+		events = append([]models.HistoryEvent{created}, events...)
+	}
+
+	previousSeq := project.HistorySeq
+	for i := range events {
+		events[i].Seq = previousSeq + int64(i) + 1
+		events[i].Version = expected + 1
+		events[i].ProjectID = project.ID
+	}
+	if upgraded {
+		events[0].Version = expected
+	}
+
+	// The events are on disk before the project file that commits them
+	if err := s.appendHistory(project.ID, events); err != nil {
+		return err
+	}
+
+	project.HistorySeq += int64(len(events))
+	if err := writeProject(filename, project, expected+1); err != nil {
+		project.HistorySeq = previousSeq
+		return err
+	}
+
+	s.snapshotIfDue(project, previousSeq)
+	return nil
+}
+
+// lockFile takes a project's file lock for a single save. Callers inside
+// LockProject already hold it, and a second flock on the same file would wait
+// for ourselves, so for them it does nothing.
+func (s *JSONProjectStore) lockFile(id string) (func(), error) {
+	s.mu.RLock()
+	held, timeout := s.held[id] > 0, s.lockTimeout
+	s.mu.RUnlock()
+
+	if held {
+		return func() {}, nil
+	}
+	return LockFile(s.lockPath(id), timeout)
+}
 
-	data, err := json.MarshalIndent(project, "", "  ")
+// writeProject writes a project at the given version, keeping a backup of
+// the previous file. The project's Version is only changed if the write succeeds.
+func writeProject(filename string, project *models.Project, version int64) error {
+	saved := *project
+	saved.Version = version
+
+	data, err := json.MarshalIndent(&saved, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal project: %w", err)
 	}
 
-	if err := os.WriteFile(filename, data, 0644); err != nil {
+	if err := backupFile(filename); err != nil {
+		return fmt.Errorf("failed to back up project file: %w", err)
+	}
+
+	if err := WriteFileAtomic(filename, data, 0644); err != nil {
 		return fmt.Errorf("failed to write project file: %w", err)
 	}
 
+	project.Version = version
 	return nil
 }
 
+// storedVersion returns the version of a project file, or 0 if it does not exist
+func storedVersion(filename string) (int64, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read project file: %w", err)
+	}
+
+	var stored struct {
+		Version int64 `json:"version"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return 0, &CorruptFileError{Path: filename, Err: err}
+	}
+	return stored.Version, nil
+}
+
+// projectPath returns the file a project is stored in
+func (s *JSONProjectStore) projectPath(id string) string {
+	return filepath.Join(s.dataDir, fmt.Sprintf("project_%s.json", id))
+}
+
 // GetProject retrieves a project from storage
 func (s *JSONProjectStore) GetProject(id string) (*models.Project, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	lock := s.files.For(id)
+	lock.RLock()
+	defer lock.RUnlock()
 
-	filename := filepath.Join(s.dataDir, fmt.Sprintf("project_%s.json", id))
+	project, err := readProject(s.projectPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrProjectNotFound, id)
+	}
+	return project, err
+}
 
+// readProject reads and parses a project file
+func readProject(filename string) (*models.Project, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("project not found: %w", err)
-		}
 		return nil, fmt.Errorf("failed to read project file: %w", err)
 	}
 
 	var project models.Project
 	if err := json.Unmarshal(data, &project); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal project: %w", err)
+		return nil, &CorruptFileError{Path: filename, Err: err}
 	}
 
 	return &project, nil
 }
 
-// ListProjects returns all projects
+// ListProjects returns all projects. Project files that cannot be read or
+// parsed are reported as CorruptFileErrors joined into the returned error; the
+// projects that did load are returned alongside it. Project files are only
+// ever replaced by rename, so they can be read without taking any locks.
 func (s *JSONProjectStore) ListProjects() ([]*models.Project, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	files, err := filepath.Glob(filepath.Join(s.dataDir, "project_*.json"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list project files: %w", err)
 	}
 
 	var projects []*models.Project
+	var corrupt []error
 	for _, file := range files {
 		data, err := os.ReadFile(file)
 		if err != nil {
-			continue // Skip files that can't be read
+			corrupt = append(corrupt, &CorruptFileError{Path: file, Err: err})
+			continue
 		}
 
 		var project models.Project
 		if err := json.Unmarshal(data, &project); err != nil {
-			continue // Skip files that can't be unmarshaled
+			corrupt = append(corrupt, &CorruptFileError{Path: file, Err: err})
+			continue
 		}
 
 		projects = append(projects, &project)
 	}
 
-	return projects, nil
+	return projects, errors.Join(corrupt...)
 }
 
 // DeleteProject removes a project from storage
 func (s *JSONProjectStore) DeleteProject(id string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	lock := s.files.For(id)
+	lock.Lock()
+	defer lock.Unlock()
 
-	filename := filepath.Join(s.dataDir, fmt.Sprintf("project_%s.json", id))
+	filename := s.projectPath(id)
 
 	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to delete project file: %w", err)
 	}
+	if err := os.Remove(filename + ".bak"); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete project backup: %w", err)
+	}
+	if err := s.deleteHistory(id); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"time"
+)
+
+// DefaultLockTimeout is how long LockProject waits for another process
+const DefaultLockTimeout = 10 * time.Second
+
+// SetLockTimeout sets how long LockProject waits before returning ErrLockTimeout
+func (s *JSONProjectStore) SetLockTimeout(timeout time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lockTimeout = timeout
+}
+
+// LockProject takes an exclusive advisory lock on a project, shared with
+// every process using the same data directory. The lock lives in
+// project_<id>.lock and is released by the returned function, or by the
+// operating system if the process exits.
+func (s *JSONProjectStore) LockProject(id string) (func(), error) {
+	s.mu.RLock()
+	timeout := s.lockTimeout
+	s.mu.RUnlock()
+
+	unlock, err := LockFile(s.lockPath(id), timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.held[id]++
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		s.held[id]--
+		if s.held[id] == 0 {
+			delete(s.held, id)
+		}
+		s.mu.Unlock()
+		unlock()
+	}, nil
+}
+
+// lockPath returns the lock file for a project
+func (s *JSONProjectStore) lockPath(id string) string {
+	return filepath.Join(s.dataDir, fmt.Sprintf("project_%s.lock", id))
+}
+
+// LockFile takes an exclusive advisory lock on path, shared with other
+// processes, polling for it until timeout. The returned function releases it.
+func LockFile(path string, timeout time.Duration) (func(), error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		unlock, acquired, err := tryLockFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock %s: %w", filepath.Base(path), err)
+		}
+		if acquired {
+			return unlock, nil
+		}
+
+		if !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("%w: %s held for over %v", ErrLockTimeout, filepath.Base(path), timeout)
+		}
+		// Jitter keeps waiting processes from retrying in lockstep
+		time.Sleep(time.Duration(5+rand.Intn(20)) * time.Millisecond)
+	}
+}
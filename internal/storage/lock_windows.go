@@ -0,0 +1,39 @@
+//go:build windows
+
+package storage
+
+import (
+	"errors"
+	"syscall"
+)
+
+// errSharingViolation is ERROR_SHARING_VIOLATION, returned when another
+// handle has the file open without sharing
+const errSharingViolation syscall.Errno = 32
+
+// tryLockFile opens path without sharing, which excludes every other process
+// until the handle is closed
+func tryLockFile(path string) (func(), bool, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	handle, err := syscall.CreateFile(name,
+		syscall.GENERIC_READ|syscall.GENERIC_WRITE,
+		0, // no sharing
+		nil,
+		syscall.OPEN_ALWAYS,
+		syscall.FILE_ATTRIBUTE_NORMAL,
+		0)
+	if err != nil {
+		if errors.Is(err, errSharingViolation) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return func() {
+		syscall.CloseHandle(handle)
+	}, true, nil
+}
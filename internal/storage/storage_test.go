@@ -0,0 +1,318 @@
+package storage_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bneil/voter/internal/models"
+	"github.com/bneil/voter/internal/storage"
+)
+
+func TestSaveProjectKeepsBackup(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewJSONProjectStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	project := models.NewProject("demo", "Demo", 2, 10)
+	if err := store.SaveProject(project); err != nil {
+		t.Fatalf("Failed to save project: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "project_demo.json.bak")); !os.IsNotExist(err) {
+		t.Errorf("Expected no backup after the first save, got %v", err)
+	}
+
+	project.Name = "Renamed"
+	if err := store.SaveProject(project); err != nil {
+		t.Fatalf("Failed to save project: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "project_demo.json.bak"))
+	if err != nil {
+		t.Fatalf("Failed to read backup: %v", err)
+	}
+	var previous models.Project
+	if err := json.Unmarshal(data, &previous); err != nil {
+		t.Fatalf("Failed to parse backup: %v", err)
+	}
+	if previous.Name != "Demo" {
+		t.Errorf("Expected the backup to hold the previous version, got name %q", previous.Name)
+	}
+
+	saved, err := store.GetProject("demo")
+	if err != nil || saved.Name != "Renamed" {
+		t.Errorf("Expected the new version to be stored, got %v (%v)", saved, err)
+	}
+
+	// Only the project, its backup and its lock file remain; temp files are
+	// renamed or removed
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read data directory: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("Expected 3 files in the data directory, got %d", len(entries))
+	}
+
+	if err := store.DeleteProject("demo"); err != nil {
+		t.Fatalf("Failed to delete project: %v", err)
+	}
+	// The lock file stays, since another process may be waiting on it
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 || entries[0].Name() != "project_demo.lock" {
+		t.Errorf("Expected delete to remove the backup too, %d files left", len(entries))
+	}
+}
+
+func TestListProjectsReportsCorruptFiles(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewJSONProjectStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	if err := store.SaveProject(models.NewProject("good", "Good", 2, 10)); err != nil {
+		t.Fatalf("Failed to save project: %v", err)
+	}
+
+	// A write cut short by a crash
+	if err := os.WriteFile(filepath.Join(dir, "project_broken.json"), []byte(`{"id": "broken", "na`), 0644); err != nil {
+		t.Fatalf("Failed to write corrupt file: %v", err)
+	}
+
+	projects, err := store.ListProjects()
+	if !errors.Is(err, storage.ErrCorruptProject) {
+		t.Fatalf("Expected ErrCorruptProject, got %v", err)
+	}
+	var corrupt *storage.CorruptFileError
+	if !errors.As(err, &corrupt) || filepath.Base(corrupt.Path) != "project_broken.json" {
+		t.Errorf("Expected the corrupt file to be named, got %v", err)
+	}
+	if len(projects) != 1 || projects[0].ID != "good" {
+		t.Errorf("Expected the good project to still be listed, got %d projects", len(projects))
+	}
+
+	if _, err := store.GetProject("broken"); !errors.Is(err, storage.ErrCorruptProject) {
+		t.Errorf("Expected ErrCorruptProject from GetProject, got %v", err)
+	}
+}
+
+func TestSaveProjectIfVersion(t *testing.T) {
+	store, err := storage.NewJSONProjectStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	project := models.NewProject("demo", "Demo", 2, 10)
+	if err := store.SaveProjectIfVersion(project, 0); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if project.Version != 1 {
+		t.Errorf("Expected version 1 after the first save, got %d", project.Version)
+	}
+	if err := store.SaveProjectIfVersion(models.NewProject("demo", "Again", 2, 10), 0); !errors.Is(err, storage.ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict creating an existing project, got %v", err)
+	}
+
+	// Two writers load the same version; only the first may save
+	first, _ := store.GetProject("demo")
+	second, _ := store.GetProject("demo")
+	first.Name = "First"
+	if err := store.SaveProjectIfVersion(first, first.Version); err != nil {
+		t.Fatalf("Failed to save project: %v", err)
+	}
+	second.Name = "Second"
+	if err := store.SaveProjectIfVersion(second, second.Version); !errors.Is(err, storage.ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict for a stale save, got %v", err)
+	}
+	if second.Version != 1 {
+		t.Errorf("Expected a failed save to leave the version alone, got %d", second.Version)
+	}
+
+	saved, _ := store.GetProject("demo")
+	if saved.Name != "First" || saved.Version != 2 {
+		t.Errorf("Expected the first writer's save at version 2, got %q at %d", saved.Name, saved.Version)
+	}
+
+	// Unconditional saves never move the version backwards
+	if err := store.SaveProject(second); err != nil {
+		t.Fatalf("Failed to save project: %v", err)
+	}
+	if second.Version != 3 {
+		t.Errorf("Expected version 3 after overwriting, got %d", second.Version)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	if _, _, err := storage.Open("json", t.TempDir()); err != nil {
+		t.Errorf("Failed to open json store: %v", err)
+	}
+	if _, _, err := storage.Open("sql", t.TempDir()); !errors.Is(err, storage.ErrUnknownBackend) {
+		t.Errorf("Expected ErrUnknownBackend, got %v", err)
+	}
+}
+
+func TestLockProjectTimeout(t *testing.T) {
+	dir := t.TempDir()
+	holder, err := storage.NewJSONProjectStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	waiter, err := storage.NewJSONProjectStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	waiter.SetLockTimeout(50 * time.Millisecond)
+
+	unlock, err := holder.LockProject("demo")
+	if err != nil {
+		t.Fatalf("Failed to lock project: %v", err)
+	}
+
+	if _, err := waiter.LockProject("demo"); !errors.Is(err, storage.ErrLockTimeout) {
+		t.Errorf("Expected ErrLockTimeout while the lock is held, got %v", err)
+	}
+
+	// Other projects are not blocked
+	other, err := waiter.LockProject("other")
+	if err != nil {
+		t.Fatalf("Expected to lock another project, got %v", err)
+	}
+	other()
+
+	unlock()
+	again, err := waiter.LockProject("demo")
+	if err != nil {
+		t.Fatalf("Expected to lock the project once released, got %v", err)
+	}
+	again()
+}
+
+func TestProjectHistory(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewJSONProjectStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	project := models.NewProject("demo", "Demo", 2, 10)
+	created := models.HistoryEvent{Type: models.HistoryProjectCreated, Project: project}
+	if err := store.SaveProjectIfVersion(project, 0, created); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	// A project whose ID extends this one keeps its own history
+	if err := store.SaveProjectIfVersion(models.NewProject("demo_2", "Other", 2, 10), 0, created); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	// Save enough events to cross the first snapshot
+	for project.HistorySeq < storage.SnapshotInterval+2 {
+		paused := models.HistoryEvent{Type: models.HistoryProjectPaused}
+		resumed := models.HistoryEvent{Type: models.HistoryProjectResumed}
+		if err := store.SaveProjectIfVersion(project, project.Version, paused, resumed); err != nil {
+			t.Fatalf("Failed to save project: %v", err)
+		}
+	}
+
+	events, err := store.GetHistory("demo", 0)
+	if err != nil {
+		t.Fatalf("Failed to read history: %v", err)
+	}
+	if int64(len(events)) != project.HistorySeq {
+		t.Fatalf("Expected %d events, got %d", project.HistorySeq, len(events))
+	}
+	for i, event := range events {
+		if event.Seq != int64(i)+1 || event.ProjectID != "demo" {
+			t.Fatalf("Expected event %d of demo, got %d of %s", i+1, event.Seq, event.ProjectID)
+		}
+	}
+	if events[1].Version != events[2].Version || events[2].Version == events[3].Version {
+		t.Errorf("Expected events saved together to share a version, got %d, %d, %d", events[1].Version, events[2].Version, events[3].Version)
+	}
+
+	later, err := store.GetHistory("demo", 100)
+	if err != nil || len(later) != len(events)-100 || later[0].Seq != 101 {
+		t.Errorf("Expected the events after 100, got %d (%v)", len(later), err)
+	}
+
+	snapshot, err := store.GetSnapshot("demo", project.HistorySeq)
+	if err != nil || snapshot == nil {
+		t.Fatalf("Expected a snapshot, got %v (%v)", snapshot, err)
+	}
+	if snapshot.HistorySeq < storage.SnapshotInterval || snapshot.HistorySeq > project.HistorySeq {
+		t.Errorf("Expected a snapshot taken after event %d, got one at %d", storage.SnapshotInterval, snapshot.HistorySeq)
+	}
+	if early, err := store.GetSnapshot("demo", storage.SnapshotInterval-1); err != nil || early != nil {
+		t.Errorf("Expected no snapshot before event %d, got %v (%v)", storage.SnapshotInterval, early, err)
+	}
+	if other, err := store.GetSnapshot("demo_2", project.HistorySeq); err != nil || other != nil {
+		t.Errorf("Expected no snapshot for demo_2, got %v (%v)", other, err)
+	}
+
+	if err := store.DeleteProject("demo"); err != nil {
+		t.Fatalf("Failed to delete project: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "history_demo.jsonl")); !os.IsNotExist(err) {
+		t.Errorf("Expected the history to be deleted, got %v", err)
+	}
+	if snapshots, _ := filepath.Glob(filepath.Join(dir, "snapshot_*")); len(snapshots) != 0 {
+		t.Errorf("Expected the snapshots to be deleted, found %v", snapshots)
+	}
+	if other, err := store.GetHistory("demo_2", 0); err != nil || len(other) != 1 {
+		t.Errorf("Expected demo_2's history to survive, got %d events (%v)", len(other), err)
+	}
+}
+
+func TestHistorySkipsFailedSaves(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewJSONProjectStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	project := models.NewProject("demo", "Demo", 2, 10)
+	created := models.HistoryEvent{Type: models.HistoryProjectCreated, Project: project}
+	if err := store.SaveProjectIfVersion(project, 0, created); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	// A save whose events were appended but whose project file was never
+	// written, followed by one cut short by a crash mid-append
+	history, err := os.OpenFile(filepath.Join(dir, "history_demo.jsonl"), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open history: %v", err)
+	}
+	stale, _ := json.Marshal(models.HistoryEvent{Seq: 2, Type: models.HistoryProjectPaused, ProjectID: "demo"})
+	history.Write(append(stale, '\n'))
+	history.Write([]byte(`{"seq": 3, "type": "proj`))
+	history.Close()
+
+	events, err := store.GetHistory("demo", 0)
+	if err != nil {
+		t.Fatalf("Failed to read history: %v", err)
+	}
+	if len(events) != 2 || events[1].Type != models.HistoryProjectPaused {
+		t.Fatalf("Expected the created and uncommitted events, got %+v", events)
+	}
+
+	// The next save reuses the uncommitted sequence number and replaces it
+	resumed := models.HistoryEvent{Type: models.HistoryProjectResumed}
+	if err := store.SaveProjectIfVersion(project, project.Version, resumed); err != nil {
+		t.Fatalf("Failed to save project: %v", err)
+	}
+	if project.HistorySeq != 2 {
+		t.Errorf("Expected history seq 2, got %d", project.HistorySeq)
+	}
+
+	events, err = store.GetHistory("demo", 0)
+	if err != nil {
+		t.Fatalf("Failed to read history: %v", err)
+	}
+	if len(events) != 2 || events[1].Seq != 2 || events[1].Type != models.HistoryProjectResumed {
+		t.Errorf("Expected the committed events only, got %+v", events)
+	}
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// lockStripes is the number of locks that project IDs are spread over
+const lockStripes = 64
+
+// LockStripes spreads per-project locks over a fixed set of mutexes, so work
+// on different projects rarely waits on the same lock and no per-project
+// state has to be created or cleaned up. Two projects may share a stripe, so
+// a holder must never wait on another project's stripe.
+type LockStripes struct {
+	locks [lockStripes]sync.RWMutex
+}
+
+// For returns the lock for a project
+func (l *LockStripes) For(projectID string) *sync.RWMutex {
+	h := fnv.New32a()
+	h.Write([]byte(projectID))
+	return &l.locks[h.Sum32()%lockStripes]
+}
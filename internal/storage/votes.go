@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bneil/voter/internal/models"
+)
+
+// JSONVoteStore implements VoteStore using an append-only JSONL log per project
+type JSONVoteStore struct {
+	dataDir string
+	logs    LockStripes // serializes access to each project's vote log
+}
+
+// NewJSONVoteStore creates a new JSONL-based vote store
+func NewJSONVoteStore(dataDir string) (*JSONVoteStore, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	return &JSONVoteStore{
+		dataDir: dataDir,
+	}, nil
+}
+
+// SaveVote appends a vote record to the project's vote log
+func (s *JSONVoteStore) SaveVote(vote *models.Vote) error {
+	lock := s.logs.For(vote.ProjectID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	data, err := json.Marshal(vote)
+	if err != nil {
+		return fmt.Errorf("failed to marshal vote: %w", err)
+	}
+	data = append(data, '\n')
+
+	file, err := os.OpenFile(s.voteLogPath(vote.ProjectID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open vote log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("failed to append vote: %w", err)
+	}
+
+	return nil
+}
+
+// GetVotesByDecision returns all votes cast for a decision, across all projects
+func (s *JSONVoteStore) GetVotesByDecision(decisionID string) ([]*models.Vote, error) {
+	files, err := filepath.Glob(filepath.Join(s.dataDir, "votes_*.jsonl"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vote logs: %w", err)
+	}
+
+	var votes []*models.Vote
+	for _, file := range files {
+		projectID := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), "votes_"), ".jsonl")
+		lock := s.logs.For(projectID)
+		lock.RLock()
+		logVotes, err := readVoteLog(file)
+		lock.RUnlock()
+		if err != nil {
+			return nil, err
+		}
+		for _, vote := range logVotes {
+			if vote.DecisionID == decisionID {
+				votes = append(votes, vote)
+			}
+		}
+	}
+
+	return votes, nil
+}
+
+// GetVotesByProject returns all votes cast in a project, in the order they were recorded
+func (s *JSONVoteStore) GetVotesByProject(projectID string) ([]*models.Vote, error) {
+	lock := s.logs.For(projectID)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return readVoteLog(s.voteLogPath(projectID))
+}
+
+// voteLogPath returns the path of the vote log for a project
+func (s *JSONVoteStore) voteLogPath(projectID string) string {
+	return filepath.Join(s.dataDir, fmt.Sprintf("votes_%s.jsonl", projectID))
+}
+
+// readVoteLog reads every vote record from a JSONL vote log
+func readVoteLog(path string) ([]*models.Vote, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*models.Vote{}, nil
+		}
+		return nil, fmt.Errorf("failed to open vote log: %w", err)
+	}
+	defer file.Close()
+
+	votes := []*models.Vote{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var vote models.Vote
+		if err := json.Unmarshal(line, &vote); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal vote in %s: %w", filepath.Base(path), err)
+		}
+		votes = append(votes, &vote)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read vote log: %w", err)
+	}
+
+	return votes, nil
+}
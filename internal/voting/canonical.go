@@ -0,0 +1,182 @@
+package voting
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/bneil/voter/internal/models"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	ErrUnknownNormalizer = errors.New("unknown normalizer")
+	ErrInvalidNormalizer = errors.New("invalid normalizer")
+)
+
+// Normalizer rewrites a raw answer into a canonical form
+type Normalizer interface {
+	Normalize(answer string) string
+}
+
+// NormalizerFunc adapts an ordinary function to the Normalizer interface
+type NormalizerFunc func(answer string) string
+
+// Normalize calls f(answer)
+func (f NormalizerFunc) Normalize(answer string) string {
+	return f(answer)
+}
+
+// NormalizerFactory builds a normalizer from its configuration
+type NormalizerFactory func(spec models.NormalizerSpec) (Normalizer, error)
+
+var (
+	normalizersMu sync.RWMutex
+	normalizers   = map[string]NormalizerFactory{
+		"trim":     func(models.NormalizerSpec) (Normalizer, error) { return NormalizerFunc(trimSpace), nil },
+		"casefold": func(models.NormalizerSpec) (Normalizer, error) { return NormalizerFunc(strings.ToLower), nil },
+		"unicode":  func(models.NormalizerSpec) (Normalizer, error) { return NormalizerFunc(foldUnicode), nil },
+		"json":     func(models.NormalizerSpec) (Normalizer, error) { return NormalizerFunc(canonicalJSON), nil },
+		"regex":    newRegexNormalizer,
+	}
+)
+
+// RegisterNormalizer makes a normalizer type available to project settings
+func RegisterNormalizer(name string, factory NormalizerFactory) {
+	normalizersMu.Lock()
+	defer normalizersMu.Unlock()
+
+	normalizers[name] = factory
+}
+
+// NormalizerChain applies a sequence of normalizers in order
+type NormalizerChain []Normalizer
+
+// NewNormalizerChain builds a chain from project normalizer settings
+func NewNormalizerChain(specs []models.NormalizerSpec) (NormalizerChain, error) {
+	normalizersMu.RLock()
+	defer normalizersMu.RUnlock()
+
+	chain := make(NormalizerChain, 0, len(specs))
+	for _, spec := range specs {
+		factory, exists := normalizers[spec.Type]
+		if !exists {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownNormalizer, spec.Type)
+		}
+
+		normalizer, err := factory(spec)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, normalizer)
+	}
+
+	return chain, nil
+}
+
+// Normalize runs the answer through every normalizer in the chain
+func (c NormalizerChain) Normalize(answer string) string {
+	for _, normalizer := range c {
+		answer = normalizer.Normalize(answer)
+	}
+	return answer
+}
+
+// Resolve maps a raw answer to the decision option it should be counted
+// under. An option matches when its canonical form equals the answer's, so
+// declared options keep their original spelling. Answers that match no option
+// resolve to their canonical form.
+func (c NormalizerChain) Resolve(decision *models.Decision, answer string) string {
+	canonical := c.Normalize(answer)
+	for _, option := range decision.Options {
+		if option == canonical || c.Normalize(option) == canonical {
+			return option
+		}
+	}
+	return canonical
+}
+
+// ParseNormalizerSpec parses a command-line normalizer specification such as
+// "casefold" or "regex:<pattern>=><replacement>"
+func ParseNormalizerSpec(value string) (models.NormalizerSpec, error) {
+	name, rule, hasRule := strings.Cut(value, ":")
+	spec := models.NormalizerSpec{Type: name}
+	if name == "regex" {
+		if !hasRule {
+			return spec, fmt.Errorf("%w: regex requires <pattern>=><replacement>", ErrInvalidNormalizer)
+		}
+		spec.Pattern, spec.Replacement, _ = strings.Cut(rule, "=>")
+	}
+
+	if _, err := NewNormalizerChain([]models.NormalizerSpec{spec}); err != nil {
+		return spec, err
+	}
+	return spec, nil
+}
+
+// newRegexNormalizer builds a normalizer that rewrites every match of a pattern
+func newRegexNormalizer(spec models.NormalizerSpec) (Normalizer, error) {
+	if spec.Pattern == "" {
+		return nil, fmt.Errorf("%w: regex pattern is empty", ErrInvalidNormalizer)
+	}
+
+	re, err := regexp.Compile(spec.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNormalizer, err)
+	}
+
+	return NormalizerFunc(func(answer string) string {
+		return re.ReplaceAllString(answer, spec.Replacement)
+	}), nil
+}
+
+// trimSpace trims surrounding whitespace and collapses internal runs to one space
+func trimSpace(answer string) string {
+	return strings.Join(strings.Fields(answer), " ")
+}
+
+// unicodeFolds maps look-alike characters that NFKC leaves alone to their
+// plain ASCII equivalents
+var unicodeFolds = strings.NewReplacer(
+	// Zero-width characters and byte order marks
+	"\u200b", "", "\u200c", "", "\u200d", "", "\ufeff", "",
+	// Hyphens, dashes and minus signs
+	"\u2010", "-", "\u2011", "-", "\u2012", "-", "\u2013", "-", "\u2014", "-", "\u2212", "-",
+	// Quotes and primes
+	"\u2018", "'", "\u2019", "'", "\u201a", "'", "\u2032", "'",
+	"\u201c", "\"", "\u201d", "\"", "\u201e", "\"",
+	// Arrows
+	"\u2192", "->", "\u27f6", "->", "\u2190", "<-", "\u27f5", "<-", "\u2194", "<->", "\u21d2", "=>",
+)
+
+// foldUnicode applies Unicode NFKC normalization, which composes combining
+// marks in any script and folds fullwidth forms, typographic spaces and the
+// ellipsis, then folds typographic punctuation, arrows and invisible
+// characters to ASCII
+func foldUnicode(answer string) string {
+	return unicodeFolds.Replace(norm.NFKC.String(answer))
+}
+
+// canonicalJSON re-encodes structured answers with sorted keys and no
+// insignificant whitespace. Answers that are not valid JSON are left as-is.
+func canonicalJSON(answer string) string {
+	decoder := json.NewDecoder(strings.NewReader(answer))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return answer
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return answer
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
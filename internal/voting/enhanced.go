@@ -1,7 +1,6 @@
 package voting
 
 import (
-	"fmt"
 	"sync"
 	"time"
 
@@ -10,70 +9,53 @@ import (
 
 // EnhancedVotingService provides advanced voting capabilities with strategies
 type EnhancedVotingService struct {
-	votingService  *VotingService
 	strategicVoter *StrategicVoter
 	mu             sync.RWMutex
 }
 
-// VotingService represents the core voting functionality
-type VotingService struct {
-	mu sync.RWMutex
-}
-
 // NewEnhancedVotingService creates a new enhanced voting service
 func NewEnhancedVotingService() *EnhancedVotingService {
 	return &EnhancedVotingService{
-		votingService:  &VotingService{},
 		strategicVoter: NewStrategicVoter(),
 	}
 }
 
 // InitializeStrategies sets up the available voting strategies
 func (evs *EnhancedVotingService) InitializeStrategies() {
-	evs.strategicVoter.RegisterStrategy("random", NewRandomStrategy())
-	evs.strategicVoter.RegisterStrategy("consensus", NewConsensusStrategy())
-	evs.strategicVoter.RegisterStrategy("optimal", NewOptimalStrategy("general"))
+	evs.InitializeSeededStrategies(nil)
 }
 
-// CastStrategicVote casts a vote using a specific strategy
-func (evs *EnhancedVotingService) CastStrategicVote(project *models.Project, decision *models.Decision, agentID, strategyName string) error {
+// InitializeSeededStrategies sets up the available voting strategies, giving
+// each strategy named in seeds a deterministic random source so simulations
+// can be reproduced. Other strategies are seeded from the clock.
+func (evs *EnhancedVotingService) InitializeSeededStrategies(seeds map[string]int64) {
 	evs.mu.Lock()
 	defer evs.mu.Unlock()
 
-	if decision.State != models.DecisionStateVoting {
-		return fmt.Errorf("voting is closed for this decision")
-	}
-
-	// Use strategy to decide vote
-	chosenOption := evs.strategicVoter.DecideVote(strategyName, project, decision, agentID)
-
-	// Cast the vote
-	if !decision.AddVote(chosenOption) {
-		return fmt.Errorf("invalid voting option: %s", chosenOption)
+	seed := func(name string) int64 {
+		if s, ok := seeds[name]; ok {
+			return s
+		}
+		return time.Now().UnixNano()
 	}
 
-	return nil
+	evs.strategicVoter.RegisterStrategy("random", NewSeededRandomStrategy(seed("random")))
+	evs.strategicVoter.RegisterStrategy("consensus", NewSeededConsensusStrategy(seed("consensus")))
+	evs.strategicVoter.RegisterStrategy("optimal", NewSeededOptimalStrategy("general", seed("optimal")))
 }
 
-// SimulateAgentVoting simulates multiple agents voting using different strategies
-func (evs *EnhancedVotingService) SimulateAgentVoting(project *models.Project, decision *models.Decision, agentCount int) error {
-	evs.mu.Lock()
-	defer evs.mu.Unlock()
+// SimulationStrategies are the strategies simulated agents take turns using
+var SimulationStrategies = []string{"random", "consensus", "optimal"}
 
-	strategies := []string{"random", "consensus", "optimal"}
-
-	for i := 0; i < agentCount; i++ {
-		agentID := fmt.Sprintf("agent_%d", i)
-		strategy := strategies[i%len(strategies)]
-
-		chosenOption := evs.strategicVoter.DecideVote(strategy, project, decision, agentID)
-
-		if !decision.AddVote(chosenOption) {
-			return fmt.Errorf("failed to cast vote for agent %s: invalid option %s", agentID, chosenOption)
-		}
-	}
+// ChooseVote returns the option the named strategy picks for an agent. It
+// matches project.VoteChooser, so votes are cast through the project service.
+// Strategies are safe for concurrent use, so votes for different projects can
+// be chosen in parallel.
+func (evs *EnhancedVotingService) ChooseVote(strategyName string, project *models.Project, decision *models.Decision, agentID string) string {
+	evs.mu.RLock()
+	defer evs.mu.RUnlock()
 
-	return nil
+	return evs.strategicVoter.DecideVote(strategyName, project, decision, agentID)
 }
 
 // AnalyzeVotingPatterns analyzes voting patterns in a completed decision
@@ -127,7 +109,7 @@ func (evs *EnhancedVotingService) GetVotingRecommendations(project *models.Proje
 
 	// Check if voting is taking too long
 	if decision.CompletedAt == nil {
-		votingDuration := time.Since(decision.VotingStarted)
+		votingDuration := decision.VotingDuration(time.Now())
 		if votingDuration > 5*time.Minute {
 			recommendations = append(recommendations, "Consider reducing the K-ahead threshold to speed up consensus")
 		}
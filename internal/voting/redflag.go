@@ -0,0 +1,74 @@
+package voting
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bneil/voter/internal/models"
+)
+
+// RedFlagFilter discards answers that show signs of an unreliable sample, such
+// as overlong output or a malformed format, before they are counted
+type RedFlagFilter struct {
+	rules   models.RedFlagRules
+	pattern *regexp.Regexp
+}
+
+// NewRedFlagFilter creates a filter from project red-flag rules
+func NewRedFlagFilter(rules models.RedFlagRules) (*RedFlagFilter, error) {
+	filter := &RedFlagFilter{rules: rules}
+
+	if rules.RequiredPattern != "" {
+		// Anchor the pattern so it has to describe the entire answer
+		pattern, err := regexp.Compile(`^(?:` + rules.RequiredPattern + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid required pattern: %w", err)
+		}
+		filter.pattern = pattern
+	}
+
+	return filter, nil
+}
+
+// Check returns the reason an answer is red-flagged, or an empty string if it passes
+func (f *RedFlagFilter) Check(answer string) string {
+	if f.rules.MaxLength > 0 {
+		if length := utf8.RuneCountInString(answer); length > f.rules.MaxLength {
+			return fmt.Sprintf("answer length %d exceeds maximum of %d", length, f.rules.MaxLength)
+		}
+	}
+
+	for _, token := range f.rules.BannedTokens {
+		if token != "" && strings.Contains(answer, token) {
+			return fmt.Sprintf("answer contains banned token %q", token)
+		}
+	}
+
+	if f.pattern != nil && !f.pattern.MatchString(answer) {
+		return fmt.Sprintf("answer does not match required pattern %q", f.rules.RequiredPattern)
+	}
+
+	if f.rules.RequireJSON || len(f.rules.RequiredJSONKeys) > 0 {
+		var value interface{}
+		if err := json.Unmarshal([]byte(answer), &value); err != nil {
+			return "answer is not valid JSON"
+		}
+
+		if len(f.rules.RequiredJSONKeys) > 0 {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return "answer is not a JSON object"
+			}
+			for _, key := range f.rules.RequiredJSONKeys {
+				if _, exists := object[key]; !exists {
+					return fmt.Sprintf("answer is missing required key %q", key)
+				}
+			}
+		}
+	}
+
+	return ""
+}
@@ -2,6 +2,7 @@ package voting
 
 import (
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/bneil/voter/internal/models"
@@ -13,14 +14,38 @@ type Strategy interface {
 	DecideVote(project *models.Project, decision *models.Decision, agentID string) string
 }
 
+// lockedRand is a random source that is safe for concurrent use, so one
+// strategy can choose votes for many projects at once
+type lockedRand struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func newLockedRand(seed int64) *lockedRand {
+	return &lockedRand{rng: rand.New(rand.NewSource(seed))}
+}
+
+// Intn returns a random number in [0, n)
+func (r *lockedRand) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rng.Intn(n)
+}
+
 // RandomStrategy votes randomly among available options
 type RandomStrategy struct {
-	rng *rand.Rand
+	rng *lockedRand
 }
 
 func NewRandomStrategy() *RandomStrategy {
+	return NewSeededRandomStrategy(time.Now().UnixNano())
+}
+
+// NewSeededRandomStrategy creates a random strategy with a deterministic source
+func NewSeededRandomStrategy(seed int64) *RandomStrategy {
 	return &RandomStrategy{
-		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
+		rng: newLockedRand(seed),
 	}
 }
 
@@ -32,10 +57,20 @@ func (s *RandomStrategy) DecideVote(project *models.Project, decision *models.De
 }
 
 // ConsensusStrategy tries to vote for options that are gaining consensus
-type ConsensusStrategy struct{}
+type ConsensusStrategy struct {
+	rng *lockedRand
+}
 
 func NewConsensusStrategy() *ConsensusStrategy {
-	return &ConsensusStrategy{}
+	return NewSeededConsensusStrategy(time.Now().UnixNano())
+}
+
+// NewSeededConsensusStrategy creates a consensus strategy whose random
+// choices, made while there is no leader, use a deterministic source
+func NewSeededConsensusStrategy(seed int64) *ConsensusStrategy {
+	return &ConsensusStrategy{
+		rng: newLockedRand(seed),
+	}
 }
 
 func (s *ConsensusStrategy) DecideVote(project *models.Project, decision *models.Decision, agentID string) string {
@@ -43,16 +78,8 @@ func (s *ConsensusStrategy) DecideVote(project *models.Project, decision *models
 		return ""
 	}
 
-	// Find the option with the most votes
-	maxVotes := 0
-	var leadingOption string
-
-	for option, votes := range decision.Votes {
-		if votes > maxVotes {
-			maxVotes = votes
-			leadingOption = option
-		}
-	}
+	// Find the option with the most votes, using the decision's tie-break rule
+	leadingOption, maxVotes := decision.Leader()
 
 	// If there's a clear leader, vote for it
 	if leadingOption != "" && maxVotes > 0 {
@@ -60,19 +87,26 @@ func (s *ConsensusStrategy) DecideVote(project *models.Project, decision *models
 	}
 
 	// Otherwise vote randomly
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-	return decision.Options[rng.Intn(len(decision.Options))]
+	return decision.Options[s.rng.Intn(len(decision.Options))]
 }
 
 // OptimalStrategy uses game-specific knowledge to make optimal decisions
 // This is a placeholder for more sophisticated strategies
 type OptimalStrategy struct {
-	gameType string
+	gameType  string
+	consensus *ConsensusStrategy
 }
 
 func NewOptimalStrategy(gameType string) *OptimalStrategy {
+	return NewSeededOptimalStrategy(gameType, time.Now().UnixNano())
+}
+
+// NewSeededOptimalStrategy creates an optimal strategy whose fallbacks use a
+// deterministic source
+func NewSeededOptimalStrategy(gameType string, seed int64) *OptimalStrategy {
 	return &OptimalStrategy{
-		gameType: gameType,
+		gameType:  gameType,
+		consensus: NewSeededConsensusStrategy(seed),
 	}
 }
 
@@ -83,8 +117,7 @@ func (s *OptimalStrategy) DecideVote(project *models.Project, decision *models.D
 		return s.decideTowerOfHanoi(project, decision)
 	default:
 		// Fall back to consensus strategy
-		strategy := NewConsensusStrategy()
-		return strategy.DecideVote(project, decision, agentID)
+		return s.consensus.DecideVote(project, decision, agentID)
 	}
 }
 
@@ -101,8 +134,7 @@ func (s *OptimalStrategy) decideTowerOfHanoi(project *models.Project, decision *
 	}
 
 	// Fall back to random
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-	return decision.Options[rng.Intn(len(decision.Options))]
+	return decision.Options[s.consensus.rng.Intn(len(decision.Options))]
 }
 
 // StrategicVoter manages different voting strategies
@@ -0,0 +1,178 @@
+package voting_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bneil/voter/internal/models"
+	"github.com/bneil/voter/internal/voting"
+)
+
+func TestNormalizerChain(t *testing.T) {
+	chain, err := voting.NewNormalizerChain([]models.NormalizerSpec{
+		{Type: "trim"},
+		{Type: "unicode"},
+		{Type: "casefold"},
+		{Type: "regex", Pattern: `\s*->\s*`, Replacement: "->"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build normalizer chain: %v", err)
+	}
+
+	for _, answer := range []string{"A->B", "a -> b", "A→B", "  Ａ->B  "} {
+		if got := chain.Normalize(answer); got != "a->b" {
+			t.Errorf("Normalize(%q) = %q, expected %q", answer, got, "a->b")
+		}
+	}
+}
+
+func TestUnicodeNormalizerComposesMarks(t *testing.T) {
+	chain, err := voting.NewNormalizerChain([]models.NormalizerSpec{{Type: "unicode"}})
+	if err != nil {
+		t.Fatalf("Failed to build normalizer chain: %v", err)
+	}
+
+	for decomposed, composed := range map[string]string{
+		"caf\u0065\u0301": "caf\u00e9",
+		"Z\u030curich":    "\u017durich",
+		"\uff4e\u0303o":   "\u00f1o",
+		"e\u0301\u0301":   "\u00e9\u0301",
+		"q\u0301":         "q\u0301",
+		"\u00e9 \u2192 e": "\u00e9 -> e",
+		"\u03b1\u0301":    "\u03ac",
+		"\u0438\u0306":    "\u0439",
+		"e\u0302\u0301":   "\u1ebf",
+		"o\u031b\u0300":   "\u1edd",
+		"a\u00a0b\u2026":  "a b...",
+	} {
+		if got := chain.Normalize(decomposed); got != composed {
+			t.Errorf("Normalize(%q) = %q, expected %q", decomposed, got, composed)
+		}
+	}
+
+	if chain.Normalize("e\u0301") != chain.Normalize("\u00e9") {
+		t.Error("Expected e followed by a combining acute to match the precomposed form")
+	}
+}
+
+func TestNormalizerChainResolve(t *testing.T) {
+	chain, err := voting.NewNormalizerChain([]models.NormalizerSpec{{Type: "casefold"}})
+	if err != nil {
+		t.Fatalf("Failed to build normalizer chain: %v", err)
+	}
+
+	decision := models.NewDecision("test", "project", "test", 1, []string{"Left", "Right"})
+
+	// Declared options keep their original spelling
+	if got := chain.Resolve(decision, "LEFT"); got != "Left" {
+		t.Errorf("Expected LEFT to resolve to Left, got %q", got)
+	}
+
+	// Unknown answers resolve to their canonical form
+	if got := chain.Resolve(decision, "UP"); got != "up" {
+		t.Errorf("Expected UP to resolve to up, got %q", got)
+	}
+}
+
+func TestCanonicalJSONNormalizer(t *testing.T) {
+	chain, err := voting.NewNormalizerChain([]models.NormalizerSpec{{Type: "json"}})
+	if err != nil {
+		t.Fatalf("Failed to build normalizer chain: %v", err)
+	}
+
+	got := chain.Normalize(`{ "to": "C", "disk": 1, "from": "A" }`)
+	if expected := `{"disk":1,"from":"A","to":"C"}`; got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	if got := chain.Normalize("not json"); got != "not json" {
+		t.Errorf("Expected non-JSON answers to pass through, got %q", got)
+	}
+}
+
+func TestParseNormalizerSpec(t *testing.T) {
+	spec, err := voting.ParseNormalizerSpec(`regex:\s+=> `)
+	if err != nil {
+		t.Fatalf("Failed to parse regex spec: %v", err)
+	}
+	if spec.Type != "regex" || spec.Pattern != `\s+` || spec.Replacement != " " {
+		t.Errorf("Unexpected spec: %+v", spec)
+	}
+
+	if _, err := voting.ParseNormalizerSpec("soundex"); !errors.Is(err, voting.ErrUnknownNormalizer) {
+		t.Errorf("Expected ErrUnknownNormalizer, got %v", err)
+	}
+
+	if _, err := voting.ParseNormalizerSpec("regex:([=>x"); !errors.Is(err, voting.ErrInvalidNormalizer) {
+		t.Errorf("Expected ErrInvalidNormalizer, got %v", err)
+	}
+}
+
+func TestRedFlagFilter(t *testing.T) {
+	filter, err := voting.NewRedFlagFilter(models.RedFlagRules{
+		MaxLength:        40,
+		RequiredJSONKeys: []string{"move"},
+		BannedTokens:     []string{"I think"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create filter: %v", err)
+	}
+
+	tests := []struct {
+		answer  string
+		flagged bool
+	}{
+		{`{"move": "A->B"}`, false},
+		{`{"move": "A->B", "reasoning": "because it is the smallest disk"}`, true},
+		{`{"step": 1}`, true},
+		{`["A->B"]`, true},
+		{`A->B`, true},
+		{`{"move": "I think A->B"}`, true},
+	}
+
+	for _, tt := range tests {
+		if reason := filter.Check(tt.answer); (reason != "") != tt.flagged {
+			t.Errorf("Check(%q) = %q, expected flagged=%t", tt.answer, reason, tt.flagged)
+		}
+	}
+}
+
+func TestRedFlagFilterRequiredPattern(t *testing.T) {
+	filter, err := voting.NewRedFlagFilter(models.RedFlagRules{RequiredPattern: `[A-C]->[A-C]`})
+	if err != nil {
+		t.Fatalf("Failed to create filter: %v", err)
+	}
+
+	if reason := filter.Check("A->B"); reason != "" {
+		t.Errorf("Expected A->B to pass, got %q", reason)
+	}
+
+	// The pattern must match the whole answer, not just part of it
+	if reason := filter.Check("move A->B now"); reason == "" {
+		t.Error("Expected partial match to be flagged")
+	}
+
+	if _, err := voting.NewRedFlagFilter(models.RedFlagRules{RequiredPattern: "("}); err == nil {
+		t.Error("Expected invalid pattern to be rejected")
+	}
+}
+
+func TestSeededRandomStrategy(t *testing.T) {
+	decision := models.NewDecision("decision_1", "demo", "Pick", 1, []string{"A", "B", "C", "D"})
+
+	picks := func() []string {
+		strategy := voting.NewSeededRandomStrategy(42)
+		var chosen []string
+		for i := 0; i < 20; i++ {
+			chosen = append(chosen, strategy.DecideVote(nil, decision, "agent"))
+		}
+		return chosen
+	}
+
+	first, second := picks(), picks()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Expected the same seed to give the same votes, got %v and %v", first, second)
+		}
+	}
+}
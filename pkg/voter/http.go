@@ -0,0 +1,196 @@
+package voter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bneil/voter/internal/api"
+)
+
+// HTTPClient is a Client for a server started with `voter serve`
+type HTTPClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPClient creates a client for the server at baseURL, such as
+// "http://localhost:8080". A nil httpClient uses http.DefaultClient.
+func NewHTTPClient(baseURL string, httpClient *http.Client) *HTTPClient {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &HTTPClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  httpClient,
+	}
+}
+
+// APIError is returned for error responses from the server. It unwraps to
+// the matching sentinel error, so errors.Is works as it does with Local.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+// Error returns the server's error message
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the sentinel error for the error code, if there is one
+func (e *APIError) Unwrap() error {
+	return api.ErrorForCode(e.Code)
+}
+
+// CreateProject creates a new project
+func (c *HTTPClient) CreateProject(ctx context.Context, req CreateProjectRequest) (*Project, error) {
+	var p Project
+	if err := c.do(ctx, http.MethodPost, "/projects", req, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListProjects returns every project. If some project files are corrupt, the
+// projects that loaded are returned with an error wrapping ErrCorruptProject.
+func (c *HTTPClient) ListProjects(ctx context.Context) ([]*Project, error) {
+	var resp api.ListProjectsResponse
+	if err := c.do(ctx, http.MethodGet, "/projects", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	var corrupt []error
+	for _, e := range resp.Errors {
+		corrupt = append(corrupt, &APIError{StatusCode: http.StatusOK, Code: e.Code, Message: e.Error})
+	}
+	return resp.Projects, errors.Join(corrupt...)
+}
+
+// ProjectStatus returns a project and its current decision
+func (c *HTTPClient) ProjectStatus(ctx context.Context, projectID string) (*ProjectStatus, error) {
+	var status ProjectStatus
+	if err := c.do(ctx, http.MethodGet, "/projects/"+url.PathEscape(projectID), nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Progress returns per-decision progress for a project
+func (c *HTTPClient) Progress(ctx context.Context, projectID string) (*ProjectProgress, error) {
+	var progress ProjectProgress
+	if err := c.do(ctx, http.MethodGet, "/projects/"+url.PathEscape(projectID)+"/progress", nil, &progress); err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// StartDecision starts the next decision in a project
+func (c *HTTPClient) StartDecision(ctx context.Context, projectID string, req StartDecisionRequest) (*Decision, error) {
+	var decision Decision
+	if err := c.do(ctx, http.MethodPost, "/projects/"+url.PathEscape(projectID)+"/decisions", req, &decision); err != nil {
+		return nil, err
+	}
+	return &decision, nil
+}
+
+// CastVote casts an agent's answer and returns the decision after the vote
+func (c *HTTPClient) CastVote(ctx context.Context, projectID, decisionID, agentID, answer string) (*Decision, error) {
+	path := "/projects/" + url.PathEscape(projectID) + "/decisions/" + url.PathEscape(decisionID) + "/votes"
+	var resp api.CastVoteResponse
+	if err := c.do(ctx, http.MethodPost, path, api.CastVoteRequest{AgentID: agentID, Answer: answer}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Decision, nil
+}
+
+// CancelDecision cancels a decision that is still open for voting or was escalated
+func (c *HTTPClient) CancelDecision(ctx context.Context, projectID, decisionID, reason string) error {
+	path := "/projects/" + url.PathEscape(projectID) + "/decisions/" + url.PathEscape(decisionID) + "/cancel"
+	return c.do(ctx, http.MethodPost, path, api.CancelRequest{Reason: reason}, nil)
+}
+
+// Advance starts the next turn once the previous decision has a winner.
+// At max turns the project is completed instead and a nil decision is returned.
+func (c *HTTPClient) Advance(ctx context.Context, projectID string, req AdvanceRequest) (*Decision, error) {
+	var resp api.AdvanceResponse
+	if err := c.do(ctx, http.MethodPost, "/projects/"+url.PathEscape(projectID)+"/advance", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Decision, nil
+}
+
+// PauseProject pauses voting and deadline clocks
+func (c *HTTPClient) PauseProject(ctx context.Context, projectID string) error {
+	return c.do(ctx, http.MethodPost, "/projects/"+url.PathEscape(projectID)+"/pause", nil, nil)
+}
+
+// ResumeProject resumes a paused project
+func (c *HTTPClient) ResumeProject(ctx context.Context, projectID string) error {
+	return c.do(ctx, http.MethodPost, "/projects/"+url.PathEscape(projectID)+"/resume", nil, nil)
+}
+
+// EndProject completes a project, closing any open decision
+func (c *HTTPClient) EndProject(ctx context.Context, projectID string) error {
+	return c.do(ctx, http.MethodPost, "/projects/"+url.PathEscape(projectID)+"/end", nil, nil)
+}
+
+// CancelProject aborts a project without completing it
+func (c *HTTPClient) CancelProject(ctx context.Context, projectID, reason string) error {
+	return c.do(ctx, http.MethodPost, "/projects/"+url.PathEscape(projectID)+"/cancel", api.CancelRequest{Reason: reason}, nil)
+}
+
+// Watch calls handle for each event in a project until ctx is cancelled
+func (c *HTTPClient) Watch(ctx context.Context, projectID string, handle func(Event)) error {
+	err := api.Watch(ctx, c.client, c.baseURL, projectID, handle)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// do sends a JSON request and decodes the JSON response into out, unless out is nil
+func (c *HTTPClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reader).Encode(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, &reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var apiErr api.ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil || apiErr.Error == "" {
+			apiErr.Error = resp.Status
+		}
+		return &APIError{StatusCode: resp.StatusCode, Code: apiErr.Code, Message: apiErr.Error}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
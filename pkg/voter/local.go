@@ -0,0 +1,181 @@
+package voter
+
+import (
+	"context"
+
+	"github.com/bneil/voter/internal/metrics"
+	"github.com/bneil/voter/internal/project"
+	"github.com/bneil/voter/internal/storage"
+)
+
+// Local is a Client that runs the voting engine in-process
+type Local struct {
+	service     *project.Service
+	progression *project.ProgressionManager
+}
+
+// NewLocal creates an in-process client storing projects and votes in dataDir.
+// Like voter serve, it records scores and strategy stats in dataDir's metrics.json.
+func NewLocal(dataDir string) (*Local, error) {
+	store, err := storage.NewJSONProjectStore(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	votes, err := storage.NewJSONVoteStore(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	service := project.NewService(store, votes, project.NewVotingService())
+	if _, err := metrics.TrackService(service, votes, dataDir, metrics.NewScorer(), nil); err != nil {
+		return nil, err
+	}
+	return &Local{
+		service:     service,
+		progression: project.NewProgressionManager(service),
+	}, nil
+}
+
+// CreateProject creates a new project
+func (l *Local) CreateProject(ctx context.Context, req CreateProjectRequest) (*Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	return l.service.CreateProjectWithSettings(req.ID, req.Name, req.K, req.MaxTurns, req.Settings)
+}
+
+// ListProjects returns every project. If some project files are corrupt, the
+// projects that loaded are returned with an error wrapping ErrCorruptProject.
+func (l *Local) ListProjects(ctx context.Context) ([]*Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return l.service.ListProjects()
+}
+
+// ProjectStatus returns a project and its current decision
+func (l *Local) ProjectStatus(ctx context.Context, projectID string) (*ProjectStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return l.service.GetProjectStatus(projectID)
+}
+
+// Progress returns per-decision progress for a project
+func (l *Local) Progress(ctx context.Context, projectID string) (*ProjectProgress, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return l.progression.GetProjectProgress(projectID)
+}
+
+// StartDecision starts the next decision in a project
+func (l *Local) StartDecision(ctx context.Context, projectID string, req StartDecisionRequest) (*Decision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	config, err := req.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	_, decision, err := l.service.StartDecisionWithConfig(projectID, req.ID, req.Description, req.Options, config)
+	return decision, err
+}
+
+// CastVote casts an agent's answer and returns the decision after the vote
+func (l *Local) CastVote(ctx context.Context, projectID, decisionID, agentID, answer string) (*Decision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p, err := l.service.CastVote(projectID, decisionID, agentID, answer)
+	if err != nil {
+		return nil, err
+	}
+	return p.FindDecision(decisionID), nil
+}
+
+// CancelDecision cancels a decision that is still open for voting or was escalated
+func (l *Local) CancelDecision(ctx context.Context, projectID, decisionID, reason string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return l.service.CancelDecision(projectID, decisionID, reason)
+}
+
+// Advance starts the next turn once the previous decision has a winner.
+// At max turns the project is completed instead and a nil decision is returned.
+func (l *Local) Advance(ctx context.Context, projectID string, req AdvanceRequest) (*Decision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return l.progression.AdvanceProject(projectID, req.Description, req.Options)
+}
+
+// PauseProject pauses voting and deadline clocks
+func (l *Local) PauseProject(ctx context.Context, projectID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return l.service.PauseProject(projectID)
+}
+
+// ResumeProject resumes a paused project
+func (l *Local) ResumeProject(ctx context.Context, projectID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return l.service.ResumeProject(projectID)
+}
+
+// EndProject completes a project, closing any open decision
+func (l *Local) EndProject(ctx context.Context, projectID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return l.service.EndProject(projectID)
+}
+
+// CancelProject aborts a project without completing it
+func (l *Local) CancelProject(ctx context.Context, projectID, reason string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return l.service.CancelProject(projectID, reason)
+}
+
+// Watch calls handle for each event in a project until ctx is cancelled.
+// Only changes made through this client are observed.
+func (l *Local) Watch(ctx context.Context, projectID string, handle func(Event)) error {
+	if _, err := l.service.GetProject(projectID); err != nil {
+		return err
+	}
+
+	events, cancel := l.service.Subscribe(projectID)
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event := <-events:
+			handle(event)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Package voter is the public API for embedding the First-to-Ahead-by-K voting
+// system. Client is implemented both in-process, over a local data directory,
+// and by an HTTP client for a running `voter serve`, so the two are
+// interchangeable.
+package voter
+
+import (
+	"context"
+
+	"github.com/bneil/voter/internal/api"
+	"github.com/bneil/voter/internal/models"
+	"github.com/bneil/voter/internal/project"
+)
+
+// Model types shared with the voting engine
+type (
+	Project         = models.Project
+	ProjectSettings = models.ProjectSettings
+	Decision        = models.Decision
+	Vote            = models.Vote
+	ProjectStatus   = project.ProjectStatus
+	ProjectProgress = project.ProjectProgress
+	Event           = project.Event
+	EventType       = project.EventType
+)
+
+// Request types
+type (
+	CreateProjectRequest = api.CreateProjectRequest
+	StartDecisionRequest = api.StartDecisionRequest
+	AdvanceRequest       = api.AdvanceRequest
+)
+
+// Event types
+const (
+	EventVoteCast          = project.EventVoteCast
+	EventDecisionStarted   = project.EventDecisionStarted
+	EventDecisionCompleted = project.EventDecisionCompleted
+	EventProjectCompleted  = project.EventProjectCompleted
+)
+
+// Errors returned by every Client implementation; test for them with errors.Is
+var (
+	ErrProjectNotFound   = project.ErrProjectNotFound
+	ErrCorruptProject    = project.ErrCorruptProject
+	ErrLockTimeout       = project.ErrLockTimeout
+	ErrVersionConflict   = project.ErrVersionConflict
+	ErrProjectExists     = project.ErrProjectExists
+	ErrProjectNotActive  = project.ErrProjectNotActive
+	ErrProjectPaused     = project.ErrProjectPaused
+	ErrProjectNotPaused  = project.ErrProjectNotPaused
+	ErrDecisionNotFound  = project.ErrDecisionNotFound
+	ErrDecisionActive    = project.ErrDecisionActive
+	ErrNoWinner          = project.ErrNoWinner
+	ErrReasonRequired    = project.ErrReasonRequired
+	ErrDuplicateDecision = project.ErrDuplicateDecision
+	ErrInvalidDecision   = project.ErrInvalidDecision
+	ErrInvalidSettings   = project.ErrInvalidSettings
+	ErrVotingClosed      = project.ErrVotingClosed
+	ErrDuplicateVote     = project.ErrDuplicateVote
+	ErrInvalidVote       = project.ErrInvalidVote
+	ErrVoteFlagged       = project.ErrVoteFlagged
+	ErrInvalidRequest    = api.ErrInvalidRequest
+)
+
+var (
+	_ Client = (*Local)(nil)
+	_ Client = (*HTTPClient)(nil)
+)
+
+// Client manages projects, decisions and votes
+type Client interface {
+	// CreateProject creates a new project
+	CreateProject(ctx context.Context, req CreateProjectRequest) (*Project, error)
+	// ListProjects returns every project. If some project files are corrupt,
+	// the projects that loaded are returned with an error wrapping ErrCorruptProject.
+	ListProjects(ctx context.Context) ([]*Project, error)
+	// ProjectStatus returns a project and its current decision
+	ProjectStatus(ctx context.Context, projectID string) (*ProjectStatus, error)
+	// Progress returns per-decision progress for a project
+	Progress(ctx context.Context, projectID string) (*ProjectProgress, error)
+	// StartDecision starts the next decision in a project
+	StartDecision(ctx context.Context, projectID string, req StartDecisionRequest) (*Decision, error)
+	// CastVote casts an agent's answer and returns the decision after the vote
+	CastVote(ctx context.Context, projectID, decisionID, agentID, answer string) (*Decision, error)
+	// CancelDecision cancels a decision that is still open for voting or was escalated
+	CancelDecision(ctx context.Context, projectID, decisionID, reason string) error
+	// Advance starts the next turn once the previous decision has a winner.
+	// At max turns the project is completed instead and a nil decision is returned.
+	Advance(ctx context.Context, projectID string, req AdvanceRequest) (*Decision, error)
+	// PauseProject pauses voting and deadline clocks
+	PauseProject(ctx context.Context, projectID string) error
+	// ResumeProject resumes a paused project
+	ResumeProject(ctx context.Context, projectID string) error
+	// EndProject completes a project, closing any open decision
+	EndProject(ctx context.Context, projectID string) error
+	// CancelProject aborts a project without completing it
+	CancelProject(ctx context.Context, projectID, reason string) error
+	// Watch calls handle for each event in a project until ctx is cancelled
+	Watch(ctx context.Context, projectID string, handle func(Event)) error
+}
@@ -0,0 +1,201 @@
+package voter_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/bneil/voter/internal/api"
+	"github.com/bneil/voter/internal/metrics"
+	"github.com/bneil/voter/internal/project"
+	"github.com/bneil/voter/internal/storage"
+	"github.com/bneil/voter/pkg/voter"
+)
+
+// clients returns a Local client and an HTTP client backed by separate data directories
+func clients(t *testing.T) map[string]voter.Client {
+	t.Helper()
+
+	local, err := voter.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create local client: %v", err)
+	}
+
+	dataDir := t.TempDir()
+	store, err := storage.NewJSONProjectStore(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to create project store: %v", err)
+	}
+	votes, err := storage.NewJSONVoteStore(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to create vote store: %v", err)
+	}
+	service := project.NewService(store, votes, project.NewVotingService())
+	server := httptest.NewServer(api.NewServer(service, project.NewProgressionManager(service)))
+	t.Cleanup(server.Close)
+
+	return map[string]voter.Client{
+		"local": local,
+		"http":  voter.NewHTTPClient(server.URL, server.Client()),
+	}
+}
+
+func TestClients(t *testing.T) {
+	for name, client := range clients(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			p, err := client.CreateProject(ctx, voter.CreateProjectRequest{ID: "demo", Name: "Demo", K: 2})
+			if err != nil {
+				t.Fatalf("Failed to create project: %v", err)
+			}
+			if p.MaxTurns != 10 {
+				t.Errorf("Expected default max turns of 10, got %d", p.MaxTurns)
+			}
+
+			if _, err := client.CreateProject(ctx, voter.CreateProjectRequest{ID: "demo", Name: "Demo", K: 2}); !errors.Is(err, voter.ErrProjectExists) {
+				t.Errorf("Expected ErrProjectExists, got %v", err)
+			}
+
+			decision, err := client.StartDecision(ctx, "demo", voter.StartDecisionRequest{Description: "Pick", Options: []string{"A", "B"}})
+			if err != nil {
+				t.Fatalf("Failed to start decision: %v", err)
+			}
+
+			if _, err := client.CastVote(ctx, "demo", decision.ID, "agent1", "C"); !errors.Is(err, voter.ErrInvalidVote) {
+				t.Errorf("Expected ErrInvalidVote, got %v", err)
+			}
+
+			for _, agent := range []string{"agent1", "agent2"} {
+				decision, err = client.CastVote(ctx, "demo", decision.ID, agent, "A")
+				if err != nil {
+					t.Fatalf("Failed to cast vote: %v", err)
+				}
+			}
+			if decision.Winner == nil || *decision.Winner != "A" {
+				t.Errorf("Expected A to win, got %v", decision.Winner)
+			}
+
+			if _, err := client.CastVote(ctx, "demo", decision.ID, "agent3", "B"); !errors.Is(err, voter.ErrVotingClosed) {
+				t.Errorf("Expected ErrVotingClosed, got %v", err)
+			}
+
+			status, err := client.ProjectStatus(ctx, "demo")
+			if err != nil {
+				t.Fatalf("Failed to get status: %v", err)
+			}
+			if status.Project.Metrics.TotalDecisions != 1 {
+				t.Errorf("Expected 1 completed decision, got %d", status.Project.Metrics.TotalDecisions)
+			}
+
+			progress, err := client.Progress(ctx, "demo")
+			if err != nil {
+				t.Fatalf("Failed to get progress: %v", err)
+			}
+			if progress.CompletedDecisions != 1 {
+				t.Errorf("Expected 1 completed decision, got %d", progress.CompletedDecisions)
+			}
+
+			projects, err := client.ListProjects(ctx)
+			if err != nil || len(projects) != 1 {
+				t.Errorf("Expected 1 project, got %d (%v)", len(projects), err)
+			}
+
+			// Drive the project through the rest of its lifecycle
+			if err := client.PauseProject(ctx, "demo"); err != nil {
+				t.Fatalf("Failed to pause project: %v", err)
+			}
+			if _, err := client.Advance(ctx, "demo", voter.AdvanceRequest{Description: "Next", Options: []string{"A", "B"}}); !errors.Is(err, voter.ErrProjectPaused) {
+				t.Errorf("Expected ErrProjectPaused advancing a paused project, got %v", err)
+			}
+			if err := client.ResumeProject(ctx, "demo"); err != nil {
+				t.Fatalf("Failed to resume project: %v", err)
+			}
+			if err := client.ResumeProject(ctx, "demo"); !errors.Is(err, voter.ErrProjectNotPaused) {
+				t.Errorf("Expected ErrProjectNotPaused, got %v", err)
+			}
+
+			next, err := client.Advance(ctx, "demo", voter.AdvanceRequest{Description: "Next", Options: []string{"A", "B"}})
+			if err != nil || next == nil || next.TurnNumber != 2 {
+				t.Fatalf("Expected a turn 2 decision, got %v (%v)", next, err)
+			}
+			if err := client.CancelDecision(ctx, "demo", next.ID, ""); !errors.Is(err, voter.ErrReasonRequired) {
+				t.Errorf("Expected ErrReasonRequired, got %v", err)
+			}
+			if err := client.CancelDecision(ctx, "demo", next.ID, "bad options"); err != nil {
+				t.Fatalf("Failed to cancel decision: %v", err)
+			}
+			if err := client.EndProject(ctx, "demo"); err != nil {
+				t.Fatalf("Failed to end project: %v", err)
+			}
+			if err := client.CancelProject(ctx, "demo", "too late"); err == nil {
+				t.Error("Expected cancelling a completed project to fail")
+			}
+
+			if _, err := client.CreateProject(ctx, voter.CreateProjectRequest{ID: "short", Name: "Short", K: 1, MaxTurns: 1}); err != nil {
+				t.Fatalf("Failed to create project: %v", err)
+			}
+			first, err := client.Advance(ctx, "short", voter.AdvanceRequest{Description: "Only", Options: []string{"A", "B"}})
+			if err != nil {
+				t.Fatalf("Failed to advance project: %v", err)
+			}
+			if _, err := client.CastVote(ctx, "short", first.ID, "agent1", "A"); err != nil {
+				t.Fatalf("Failed to cast vote: %v", err)
+			}
+			if last, err := client.Advance(ctx, "short", voter.AdvanceRequest{Description: "Beyond", Options: []string{"A", "B"}}); err != nil || last != nil {
+				t.Errorf("Expected advancing past max turns to complete the project, got %v (%v)", last, err)
+			}
+
+			if _, err := client.CreateProject(ctx, voter.CreateProjectRequest{ID: "doomed", Name: "Doomed", K: 2}); err != nil {
+				t.Fatalf("Failed to create project: %v", err)
+			}
+			if err := client.CancelProject(ctx, "doomed", "no longer needed"); err != nil {
+				t.Fatalf("Failed to cancel project: %v", err)
+			}
+			if status, err := client.ProjectStatus(ctx, "doomed"); err != nil || status.Project.CancelReason != "no longer needed" {
+				t.Errorf("Expected a cancelled project with its reason, got %v (%v)", status, err)
+			}
+
+			if _, err := client.ProjectStatus(ctx, "missing"); !errors.Is(err, voter.ErrProjectNotFound) {
+				t.Errorf("Expected ErrProjectNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLocalRecordsMetrics(t *testing.T) {
+	dataDir := t.TempDir()
+	client, err := voter.NewLocal(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to create local client: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := client.CreateProject(ctx, voter.CreateProjectRequest{ID: "demo", Name: "Demo", K: 1, MaxTurns: 1}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	decision, err := client.StartDecision(ctx, "demo", voter.StartDecisionRequest{Description: "Pick", Options: []string{"A", "B"}})
+	if err != nil {
+		t.Fatalf("Failed to start decision: %v", err)
+	}
+	if _, err := client.CastVote(ctx, "demo", decision.ID, "agent1", "A"); err != nil {
+		t.Fatalf("Failed to cast vote: %v", err)
+	}
+	if err := client.EndProject(ctx, "demo"); err != nil {
+		t.Fatalf("Failed to end project: %v", err)
+	}
+
+	// Scores are recorded as they would be by voter serve
+	tracker, err := metrics.LoadTracker(filepath.Join(dataDir, "metrics.json"))
+	if err != nil {
+		t.Fatalf("Failed to load metrics: %v", err)
+	}
+	if tracker.GetProjectScore("demo") == nil {
+		t.Error("Expected the project score to be recorded")
+	}
+	if tracker.GetDecisionScore(metrics.DecisionKey("demo", decision.ID)) == nil {
+		t.Error("Expected the decision score to be recorded")
+	}
+}